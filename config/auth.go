@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	DefaultPasswordResetTokenTTL = time.Hour
+	DefaultAccessTokenTTL        = 15 * time.Minute
+	DefaultRefreshTokenTTL       = 30 * 24 * time.Hour
+	DefaultLoginMaxFailures      = 5
+	DefaultLoginMaxFailuresPerIP = 20
+	DefaultLoginLockoutDuration  = 15 * time.Minute
+	DefaultLoginMaxDelay         = 30 * time.Second
+	DefaultOTPTTL                = 5 * time.Minute
+	DefaultOTPMaxAttempts        = 5
+	DefaultOTPResendInterval     = time.Minute
+	DefaultOTPMaxPerHour         = 5
+	DefaultActivationTokenTTL    = 24 * time.Hour
+	DefaultPendingUserTTL        = 7 * 24 * time.Hour
+	DefaultPasswordMinLength     = 8
+	DefaultPasswordMinClasses    = 2
+	DefaultBcryptCost            = 12
+	DefaultImpersonationTokenTTL = 15 * time.Minute
+	DefaultAccountDeletionGrace  = 14 * 24 * time.Hour
+	DefaultOAuthTokenTTL         = time.Hour
+)
+
+func GetPasswordResetTokenTTL() time.Duration {
+	return getDuration("PASSWORD_RESET_TOKEN_TTL", DefaultPasswordResetTokenTTL)
+}
+
+func GetAccessTokenTTL() time.Duration {
+	return getDuration("ACCESS_TOKEN_TTL", DefaultAccessTokenTTL)
+}
+
+func GetRefreshTokenTTL() time.Duration {
+	return getDuration("REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL)
+}
+
+func GetLoginMaxFailures() int {
+	return getInt("LOGIN_MAX_FAILURES", DefaultLoginMaxFailures)
+}
+
+func GetLoginMaxFailuresPerIP() int {
+	return getInt("LOGIN_MAX_FAILURES_PER_IP", DefaultLoginMaxFailuresPerIP)
+}
+
+func GetLoginLockoutDuration() time.Duration {
+	return getDuration("LOGIN_LOCKOUT_DURATION", DefaultLoginLockoutDuration)
+}
+
+func GetLoginMaxDelay() time.Duration {
+	return getDuration("LOGIN_MAX_DELAY", DefaultLoginMaxDelay)
+}
+
+func GetOTPTTL() time.Duration {
+	return getDuration("OTP_TTL", DefaultOTPTTL)
+}
+
+func GetOTPMaxAttempts() int {
+	return getInt("OTP_MAX_ATTEMPTS", DefaultOTPMaxAttempts)
+}
+
+func GetOTPResendInterval() time.Duration {
+	return getDuration("OTP_RESEND_INTERVAL", DefaultOTPResendInterval)
+}
+
+// GetOTPMaxPerHour caps the codes sent to a single recipient across accounts and purposes
+func GetOTPMaxPerHour() int {
+	return getInt("OTP_MAX_PER_HOUR", DefaultOTPMaxPerHour)
+}
+
+func GetActivationTokenTTL() time.Duration {
+	return getDuration("ACTIVATION_TOKEN_TTL", DefaultActivationTokenTTL)
+}
+
+// GetPendingUserTTL is how long a registration may stay unactivated before it gets deleted
+func GetPendingUserTTL() time.Duration {
+	return getDuration("PENDING_USER_TTL", DefaultPendingUserTTL)
+}
+
+// IsActivationTokenEnabled keeps the legacy /:token/activate route alive for clients that predate OTP activation
+func IsActivationTokenEnabled() bool {
+	return getBool("ACTIVATION_TOKEN_ENABLED", false)
+}
+
+func GetPasswordMinLength() int {
+	return getInt("PASSWORD_MIN_LENGTH", DefaultPasswordMinLength)
+}
+
+// GetPasswordMinClasses is how many of lowercase, uppercase, digit and symbol a password must mix
+func GetPasswordMinClasses() int {
+	value := getInt("PASSWORD_MIN_CLASSES", DefaultPasswordMinClasses)
+	if value > 4 {
+		return 4
+	}
+	return value
+}
+
+func GetBcryptCost() int {
+	value := getInt("BCRYPT_COST", DefaultBcryptCost)
+	if value < bcrypt.MinCost || value > bcrypt.MaxCost {
+		return DefaultBcryptCost
+	}
+	return value
+}
+
+func GetImpersonationTokenTTL() time.Duration {
+	return getDuration("IMPERSONATION_TOKEN_TTL", DefaultImpersonationTokenTTL)
+}
+
+// GetAccountDeletionGrace is how long a deletion request can still be cancelled before the account gets anonymised
+func GetAccountDeletionGrace() time.Duration {
+	return getDuration("ACCOUNT_DELETION_GRACE", DefaultAccountDeletionGrace)
+}
+
+func GetOAuthTokenTTL() time.Duration {
+	return getDuration("OAUTH_TOKEN_TTL", DefaultOAuthTokenTTL)
+}
+
+func getBool(key string, defaultValue bool) bool {
+	envValue, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(envValue)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getInt(key string, defaultValue int) int {
+	envValue, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(envValue)
+	if err != nil || value < 1 {
+		return defaultValue
+	}
+	return value
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	envValue, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(envValue)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -2,4 +2,6 @@ package config
 
 const (
 	TopicGeneral = "general"
+	TopicAuth    = "auth"
+	TopicUser    = "user"
 )
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"math"
+)
+
+const (
+	// EarthRadius is the mean radius of the earth in kilometers
+	EarthRadius = 6371.0
+	// KilometersPerDegree is the length of a degree of latitude in kilometers
+	KilometersPerDegree = 111.32
+)
+
+// Haversine returns the great circle distance in kilometers between two points given in degrees.
+func Haversine(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+	deltaLatitude := toRadians(latitude2 - latitude1)
+	deltaLongitude := toRadians(longitude2 - longitude1)
+	a := math.Pow(math.Sin(deltaLatitude/2), 2) +
+		math.Cos(toRadians(latitude1))*math.Cos(toRadians(latitude2))*math.Pow(math.Sin(deltaLongitude/2), 2)
+	return EarthRadius * 2 * math.Asin(math.Min(1, math.Sqrt(a)))
+}
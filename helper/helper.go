@@ -2,6 +2,8 @@ package helper
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"math/big"
 	"unsafe"
 
@@ -11,6 +13,7 @@ import (
 
 const (
 	letters = "0123456789abcdefghijklmnopqrstuvwxyz"
+	digits  = "0123456789"
 )
 
 var (
@@ -18,19 +21,32 @@ var (
 )
 
 func GenerateRandomString(length int) string {
+	return generateRandom(letters, length)
+}
+
+func GenerateRandomDigits(length int) string {
+	return generateRandom(digits, length)
+}
+
+func generateRandom(charset string, length int) string {
 	bs := make([]byte, length)
-	max := big.NewInt(int64(len(letters)))
+	max := big.NewInt(int64(len(charset)))
 	for i := 0; i < length; {
 		num, err := rand.Int(rand.Reader, max)
 		if err != nil {
 			continue
 		}
-		bs[i] = letters[num.Int64()]
+		bs[i] = charset[num.Int64()]
 		i++
 	}
 	return ByteSlice2String(bs)
 }
 
+func HashToken(token string) string {
+	hash := sha256.Sum256(String2ByteSlice(token))
+	return hex.EncodeToString(hash[:])
+}
+
 func String2ByteSlice(str string) []byte {
 	if str == "" {
 		return nil
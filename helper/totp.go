@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TOTPDigits      = 6
+	TOTPPeriod      = 30 * time.Second
+	totpSecretBytes = 20
+	// accept the previous and next code as well so clocks a few seconds off still work
+	totpSkew = 1
+)
+
+var (
+	totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// GetTOTPProvisioningURI returns the otpauth:// URI authenticator apps read from a QR code
+func GetTOTPProvisioningURI(issuer, accountName, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", TOTPDigits))
+	params.Set("period", fmt.Sprintf("%d", int64(TOTPPeriod.Seconds())))
+	label := url.PathEscape(issuer + ":" + accountName)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// ValidateTOTP implements RFC 6238 with SHA1, it returns the time step the code belongs to
+// so callers can refuse the same code twice.
+func ValidateTOTP(secret, code string, now time.Time) (step int64, ok bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil || len(code) != TOTPDigits {
+		return
+	}
+	currentStep := now.Unix() / int64(TOTPPeriod.Seconds())
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step = currentStep + int64(i)
+		if hmac.Equal(String2ByteSlice(generateTOTPCode(key, step)), String2ByteSlice(code)) {
+			ok = true
+			return
+		}
+	}
+	return 0, false
+}
+
+func generateTOTPCode(key []byte, step int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulo)
+}
@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the RFC 6238 SHA1 seed "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfc6238Vectors are the RFC 6238 appendix B SHA1 codes cut down to TOTPDigits
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateTOTPCode(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range rfc6238Vectors {
+		if code := generateTOTPCode(key, tc.unix/int64(TOTPPeriod.Seconds())); code != tc.code {
+			t.Errorf("generateTOTPCode at %d = %s, want %s", tc.unix, code, tc.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	for _, tc := range rfc6238Vectors {
+		step := tc.unix / int64(TOTPPeriod.Seconds())
+		for _, skew := range []struct {
+			name  string
+			steps int64
+			ok    bool
+		}{
+			{"current step", 0, true},
+			{"previous step", -1, true},
+			{"next step", 1, true},
+			{"two steps behind", -2, false},
+			{"two steps ahead", 2, false},
+		} {
+			// validating at a shifted time makes the vector's step the previous or next one
+			now := time.Unix(tc.unix-skew.steps*int64(TOTPPeriod.Seconds()), 0)
+			if now.Unix() < 0 {
+				continue
+			}
+			gotStep, ok := ValidateTOTP(rfc6238Secret, tc.code, now)
+			if ok != skew.ok {
+				t.Errorf("%s: ValidateTOTP(%s) at %d ok = %v, want %v", skew.name, tc.code, now.Unix(), ok, skew.ok)
+				continue
+			}
+			if ok && gotStep != step {
+				t.Errorf("%s: ValidateTOTP(%s) at %d step = %d, want %d", skew.name, tc.code, now.Unix(), gotStep, step)
+			}
+		}
+	}
+	now := time.Unix(59, 0)
+	for _, tc := range []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{"lower case secret with spaces", " gezdgnbvgy3tqojqgezdgnbvgy3tqojq ", "287082", true},
+		{"wrong code", rfc6238Secret, "287083", false},
+		{"code too short", rfc6238Secret, "28708", false},
+		{"code too long", rfc6238Secret, "94287082", false},
+		{"invalid secret", "not base32!", "287082", false},
+	} {
+		if _, ok := ValidateTOTP(tc.secret, tc.code, now); ok != tc.ok {
+			t.Errorf("%s: ValidateTOTP ok = %v, want %v", tc.name, ok, tc.ok)
+		}
+	}
+}
@@ -1,43 +1,334 @@
 package keys
 
 import (
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/helper"
+)
+
+const (
+	// legacyKeyID identifies the single app.rsa key pair used before key rotation was supported
+	legacyKeyID      = "app"
+	activeKeyIDFile  = "active"
+	privateKeySuffix = ".rsa"
+	publicKeySuffix  = ".rsa.pub"
+	keyBits          = 2048
+	reloadInterval   = time.Minute
 )
 
 var (
-	verifyKey      *rsa.PublicKey
-	signKey        *rsa.PrivateKey
-	privateKeyPath = "keys/app.rsa"
-	publicKeyPath  = "keys/app.rsa.pub"
+	ErrNoActiveKey = errors.New("keys: no active signing key, run `keys generate` first")
+	ErrUnknownKey  = errors.New("keys: unknown key id")
+
+	mu   sync.RWMutex
+	ring *keyring
 )
 
-func InitPublicKey() (*rsa.PublicKey, error) {
-	if verifyKey == nil {
-		verifyBytes, err := os.ReadFile(publicKeyPath)
-		if err != nil {
+type (
+	Key struct {
+		ID         string
+		PrivateKey *rsa.PrivateKey
+		PublicKey  *rsa.PublicKey
+	}
+
+	JWK struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+
+	keyring struct {
+		activeKeyID string
+		keys        map[string]*Key
+		loadedAt    time.Time
+	}
+)
+
+func getDir() string {
+	if dir, ok := os.LookupEnv("KEYS_DIR"); ok && dir != "" {
+		return dir
+	}
+	return "keys"
+}
+
+func GetSigningKey() (*Key, error) {
+	ring, err := getKeyring(false)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := ring.keys[ring.activeKeyID]
+	if !ok || key.PrivateKey == nil {
+		return nil, ErrNoActiveKey
+	}
+	return key, nil
+}
+
+// Sign issues an RS256 token with the active key, its kid header tells verifiers which key to use.
+func Sign(claims jwt.Claims) (string, error) {
+	signingKey, err := GetSigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.ID
+	return token.SignedString(signingKey.PrivateKey)
+}
+
+// GetVerificationKey returns the public key of either the active or a retired key,
+// tokens minted before kid headers were introduced can only come from the legacy key.
+func GetVerificationKey(keyID string) (*rsa.PublicKey, error) {
+	ring, err := getKeyring(false)
+	if err != nil {
+		return nil, err
+	}
+	if keyID == "" {
+		keyID = legacyKeyID
+	}
+	key, ok := ring.keys[keyID]
+	if !ok {
+		// another instance may have rotated the keys since our last reload
+		if ring, err = getKeyring(true); err != nil {
 			return nil, err
 		}
-		verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(verifyBytes)
-		if err != nil {
-			return nil, err
+		if key, ok = ring.keys[keyID]; !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+	return key.PublicKey, nil
+}
+
+func GetJWKS() (*JWKS, error) {
+	ring, err := getKeyring(false)
+	if err != nil {
+		return nil, err
+	}
+	keyIDs := make([]string, 0, len(ring.keys))
+	for keyID := range ring.keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+	response := JWKS{
+		Keys: make([]JWK, len(keyIDs)),
+	}
+	for i, keyID := range keyIDs {
+		publicKey := ring.keys[keyID].PublicKey
+		response.Keys[i] = JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: keyID,
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}
+	}
+	return &response, nil
+}
+
+// Generate creates the first signing key, it does nothing when an active key already exists.
+func Generate() (string, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+	if key, ok := ring.keys[ring.activeKeyID]; ok && key.PrivateKey != nil {
+		return ring.activeKeyID, nil
+	}
+	return createActiveKey()
+}
+
+// Rotate creates a new active key and retires the current one, retired keys lose their
+// private half but stay in the JWKS so tokens they signed remain valid until expiry.
+// Keys retired more than retention ago can't have signed a live token anymore and are deleted.
+func Rotate(retention time.Duration) (string, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+	keyID, err := createActiveKey()
+	if err != nil {
+		return "", err
+	}
+	if ring.activeKeyID != "" {
+		if err = os.Remove(filepath.Join(getDir(), ring.activeKeyID+privateKeySuffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	if err = prune(ring, time.Now().Add(-retention)); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+// prune deletes the public keys of ring retired before retiredBefore. Key ids are their creation
+// time in unix nanoseconds and a key is retired when the next one is created, the legacy key by
+// the first one. The last key of ring was only just retired so it is always kept.
+func prune(ring *keyring, retiredBefore time.Time) error {
+	var createdAts []int64
+	for keyID := range ring.keys {
+		if createdAt, err := strconv.ParseInt(keyID, 10, 64); err == nil {
+			createdAts = append(createdAts, createdAt)
+		}
+	}
+	sort.Slice(createdAts, func(i, j int) bool { return createdAts[i] < createdAts[j] })
+	retiredAts := map[string]int64{}
+	if _, ok := ring.keys[legacyKeyID]; ok && len(createdAts) > 0 {
+		retiredAts[legacyKeyID] = createdAts[0]
+	}
+	for i := 0; i < len(createdAts)-1; i++ {
+		retiredAts[strconv.FormatInt(createdAts[i], 10)] = createdAts[i+1]
+	}
+	dir := getDir()
+	for keyID, retiredAt := range retiredAts {
+		if keyID == ring.activeKeyID || !time.Unix(0, retiredAt).Before(retiredBefore) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, keyID+publicKeySuffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func createActiveKey() (string, error) {
+	dir := getDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	privateKey, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return "", err
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	keyID := strconv.FormatInt(time.Now().UTC().UnixNano(), 10)
+	if err = os.WriteFile(
+		filepath.Join(dir, keyID+privateKeySuffix),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}),
+		0600,
+	); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(
+		filepath.Join(dir, keyID+publicKeySuffix),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}),
+		0644,
+	); err != nil {
+		return "", err
+	}
+	// write then rename so running instances never read a half written key id
+	tmpFile := filepath.Join(dir, activeKeyIDFile+".tmp")
+	if err = os.WriteFile(tmpFile, helper.String2ByteSlice(keyID), 0644); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmpFile, filepath.Join(dir, activeKeyIDFile)); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+func getKeyring(forceReload bool) (*keyring, error) {
+	mu.RLock()
+	current := ring
+	mu.RUnlock()
+	if current != nil && time.Since(current.loadedAt) < reloadInterval && !forceReload {
+		return current, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// rate limit forced reloads, unknown kids could otherwise hit the disk on every request
+	if ring != nil && time.Since(ring.loadedAt) < time.Second {
+		return ring, nil
+	}
+	loaded, err := loadKeyring()
+	if err != nil {
+		if ring != nil {
+			return ring, nil
 		}
+		return nil, err
 	}
-	return verifyKey, nil
+	ring = loaded
+	return ring, nil
 }
 
-func InitPrivateKey() (*rsa.PrivateKey, error) {
-	if signKey == nil {
-		signBytes, err := os.ReadFile(privateKeyPath)
+func loadKeyring() (*keyring, error) {
+	dir := getDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	response := keyring{
+		keys:     map[string]*Key{},
+		loadedAt: time.Now(),
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, publicKeySuffix) {
+			continue
+		}
+		keyID := strings.TrimSuffix(name, publicKeySuffix)
+		publicKeyBytes, err := os.ReadFile(filepath.Join(dir, name))
 		if err != nil {
 			return nil, err
 		}
-		signKey, err = jwt.ParseRSAPrivateKeyFromPEM(signBytes)
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyBytes)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("keys: invalid public key %s: %w", keyID, err)
+		}
+		response.keys[keyID] = &Key{
+			ID:        keyID,
+			PublicKey: publicKey,
+		}
+	}
+	activeKeyIDBytes, err := os.ReadFile(filepath.Join(dir, activeKeyIDFile))
+	switch {
+	case err == nil:
+		response.activeKeyID = strings.TrimSpace(helper.ByteSlice2String(activeKeyIDBytes))
+	case errors.Is(err, os.ErrNotExist):
+		if _, err = os.Stat(filepath.Join(dir, legacyKeyID+privateKeySuffix)); err == nil {
+			response.activeKeyID = legacyKeyID
 		}
+	default:
+		return nil, err
+	}
+	if response.activeKeyID == "" {
+		return &response, nil
+	}
+	privateKeyBytes, err := os.ReadFile(filepath.Join(dir, response.activeKeyID+privateKeySuffix))
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("keys: invalid private key %s: %w", response.activeKeyID, err)
+	}
+	response.keys[response.activeKeyID] = &Key{
+		ID:         response.activeKeyID,
+		PrivateKey: privateKey,
+		PublicKey:  &privateKey.PublicKey,
 	}
-	return signKey, nil
+	return &response, nil
 }
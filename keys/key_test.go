@@ -0,0 +1,50 @@
+package keys
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatePrunesRetiredKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		retention time.Duration
+		pruned    bool
+	}{
+		{"retired within retention", time.Hour, false},
+		{"retired past retention", 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("KEYS_DIR", dir)
+			firstKeyID, err := Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the key retired by a rotation is always kept, it may have signed tokens a moment ago
+			secondKeyID, err := Rotate(tc.retention)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = os.Stat(filepath.Join(dir, firstKeyID+publicKeySuffix)); err != nil {
+				t.Fatalf("key %s pruned right after it was retired: %v", firstKeyID, err)
+			}
+			thirdKeyID, err := Rotate(tc.retention)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = os.Stat(filepath.Join(dir, firstKeyID+publicKeySuffix))
+			if pruned := errors.Is(err, os.ErrNotExist); pruned != tc.pruned {
+				t.Errorf("key %s pruned = %v, want %v", firstKeyID, pruned, tc.pruned)
+			}
+			for _, keyID := range []string{secondKeyID, thirdKeyID} {
+				if _, err = os.Stat(filepath.Join(dir, keyID+publicKeySuffix)); err != nil {
+					t.Errorf("key %s: %v", keyID, err)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/roysitumorang/laukpauk/config"
 	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/keys"
 	"github.com/roysitumorang/laukpauk/router"
 	"github.com/roysitumorang/laukpauk/services/messagingconsumer"
 	"github.com/spf13/cobra"
@@ -39,6 +40,9 @@ func main() {
 			g.Go(func() error {
 				return service.HTTPServerMain()
 			})
+			g.Go(func() error {
+				return service.JobMain(ctx)
+			})
 			g.Go(func() error {
 				messagingConsumer := messagingconsumer.GetMessagingConsumerService()
 				messagingConsumer.Consume(service)
@@ -82,11 +86,45 @@ func main() {
 			}
 		},
 	}
+	cmdKeys := &cobra.Command{
+		Use:   "keys",
+		Short: "generate/rotate JWT signing keys",
+		Args: func(_ *cobra.Command, args []string) (err error) {
+			if len(args) == 0 {
+				err = errors.New("requires at least 1 arg (generate|rotate)")
+				return
+			}
+			if args[0] != "generate" && args[0] != "rotate" {
+				err = fmt.Errorf("invalid first flag specified: %s", args[0])
+			}
+			return
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			if err := godotenv.Load(".env"); err != nil {
+				helper.Capture(ctx, zap.FatalLevel, err, ctxt, "ErrLoad")
+			}
+			switch args[0] {
+			case "generate":
+				keyID, err := keys.Generate()
+				if err != nil {
+					helper.Capture(ctx, zap.FatalLevel, err, ctxt, "ErrGenerate")
+				}
+				helper.Log(ctx, zap.InfoLevel, fmt.Sprintf("active signing key: %s", keyID), ctxt, "")
+			case "rotate":
+				keyID, err := keys.Rotate(config.GetRefreshTokenTTL())
+				if err != nil {
+					helper.Capture(ctx, zap.FatalLevel, err, ctxt, "ErrRotate")
+				}
+				helper.Log(ctx, zap.InfoLevel, fmt.Sprintf("rotated, new active signing key: %s", keyID), ctxt, "")
+			}
+		},
+	}
 	rootCmd := &cobra.Command{Use: config.AppName}
 	rootCmd.AddCommand(
 		cmdVersion,
 		cmdRun,
 		cmdMigration,
+		cmdKeys,
 	)
 	rootCmd.SuggestionsMinimumDistance = 1
 	_ = rootCmd.Execute()
@@ -0,0 +1,46 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	apiKeyModel "github.com/roysitumorang/laukpauk/modules/apikey/model"
+	apiKeyUseCase "github.com/roysitumorang/laukpauk/modules/apikey/usecase"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderName = "X-API-Key"
+)
+
+// NewApiKey is the machine to machine alternative to middleware/jwt plus middleware/rbac, it authenticates
+// the X-API-Key header, requires every one of scopes and stores the owning seller as *authModel.CurrentUser.
+func NewApiKey(apiKeyUseCase apiKeyUseCase.ApiKeyUseCase, scopes ...string) func(*fiber.Ctx) error {
+	ctxt := "MiddlewareApiKey-NewApiKey"
+	return func(c *fiber.Ctx) error {
+		ctx := context.Background()
+		key := c.Get(HeaderName)
+		if key == "" {
+			return helper.NewResponse(fiber.StatusUnauthorized, "missing api key", nil).WriteResponse(c)
+		}
+		currentUser, err := apiKeyUseCase.Authenticate(ctx, key)
+		if errors.Is(err, apiKeyModel.ErrInvalidApiKey) {
+			return helper.NewResponse(fiber.StatusUnauthorized, err.Error(), nil).WriteResponse(c)
+		}
+		if err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrAuthenticate")
+			return helper.NewResponse(fiber.StatusInternalServerError, err.Error(), nil).WriteResponse(c)
+		}
+		for _, scope := range scopes {
+			if !slices.Contains(currentUser.Scopes, scope) {
+				return helper.NewResponse(fiber.StatusForbidden, "api key lacks scope "+scope, nil).WriteResponse(c)
+			}
+		}
+		c.Locals(authModel.CurrentUserContextKey, currentUser)
+		return c.Next()
+	}
+}
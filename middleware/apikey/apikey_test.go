@@ -0,0 +1,123 @@
+package apikey
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	apiKeyModel "github.com/roysitumorang/laukpauk/modules/apikey/model"
+	apiKeyQuery "github.com/roysitumorang/laukpauk/modules/apikey/query"
+	apiKeyUseCase "github.com/roysitumorang/laukpauk/modules/apikey/usecase"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	userQuery "github.com/roysitumorang/laukpauk/modules/user/query"
+)
+
+type (
+	// fakeApiKeyQuery keeps api keys by the hash of their key and honours the active filter
+	fakeApiKeyQuery struct {
+		apiKeyQuery.ApiKeyQuery
+		apiKeys map[string]apiKeyModel.ApiKey
+	}
+
+	// fakeUserQuery honours the user, role and status filters
+	fakeUserQuery struct {
+		userQuery.UserQuery
+		users []userModel.User
+	}
+)
+
+func (q fakeApiKeyQuery) FindApiKeys(_ context.Context, filter apiKeyModel.ApiKeyFilter) (response []apiKeyModel.ApiKey, err error) {
+	for _, keyHash := range filter.KeyHashes {
+		if apiKey, ok := q.apiKeys[keyHash]; ok && (!filter.Active || apiKey.RevokedAt == nil) {
+			response = append(response, apiKey)
+		}
+	}
+	return
+}
+
+func (q fakeApiKeyQuery) TouchApiKey(_ context.Context, _ int64) (err error) {
+	return
+}
+
+func (q fakeUserQuery) FindUsers(_ context.Context, filter userModel.UserFilter) (response []userModel.User, err error) {
+	for _, user := range q.users {
+		if slices.Contains(filter.UserIDs, user.ID) &&
+			slices.Contains(filter.RoleIDs, user.Role.ID) &&
+			slices.Contains(filter.Status, user.Status) {
+			response = append(response, user)
+		}
+	}
+	return
+}
+
+func TestNewApiKey(t *testing.T) {
+	revokedAt := time.Now()
+	apiKeys := fakeApiKeyQuery{
+		apiKeys: map[string]apiKeyModel.ApiKey{
+			helper.HashToken("active"): {
+				ID:     1,
+				UserID: 10,
+				Scopes: []string{apiKeyModel.ScopeProductsRead},
+			},
+			helper.HashToken("revoked"): {
+				ID:        2,
+				UserID:    10,
+				Scopes:    []string{apiKeyModel.ScopeProductsRead},
+				RevokedAt: &revokedAt,
+			},
+			helper.HashToken("suspended"): {
+				ID:     3,
+				UserID: 11,
+				Scopes: []string{apiKeyModel.ScopeProductsRead},
+			},
+		},
+	}
+	users := fakeUserQuery{
+		users: []userModel.User{
+			{ID: 10, Role: roleModel.Role{ID: roleModel.RoleSeller}, Status: userModel.StatusActive},
+			{ID: 11, Role: roleModel.Role{ID: roleModel.RoleSeller}, Status: userModel.StatusSuspended},
+		},
+	}
+	useCase := apiKeyUseCase.NewApiKeyUseCase(apiKeys, users)
+	for _, tc := range []struct {
+		name       string
+		key        string
+		scopes     []string
+		statusCode int
+	}{
+		{"missing key", "", nil, fiber.StatusUnauthorized},
+		{"unknown key", "unknown", nil, fiber.StatusUnauthorized},
+		{"revoked key", "revoked", nil, fiber.StatusUnauthorized},
+		{"suspended seller", "suspended", nil, fiber.StatusUnauthorized},
+		{"missing scope", "active", []string{apiKeyModel.ScopeOrdersWrite}, fiber.StatusForbidden},
+		{"granted scope", "active", []string{apiKeyModel.ScopeProductsRead}, fiber.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", NewApiKey(useCase, tc.scopes...), func(c *fiber.Ctx) error {
+				currentUser, ok := c.Locals(authModel.CurrentUserContextKey).(*authModel.CurrentUser)
+				if !ok || currentUser.ID != 10 {
+					t.Errorf("current user = %+v, want seller 10", currentUser)
+				}
+				return c.SendStatus(fiber.StatusOK)
+			})
+			request := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tc.key != "" {
+				request.Header.Set(HeaderName, tc.key)
+			}
+			response, err := app.Test(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != tc.statusCode {
+				t.Errorf("status = %d, want %d", response.StatusCode, tc.statusCode)
+			}
+		})
+	}
+}
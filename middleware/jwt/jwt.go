@@ -1,19 +1,46 @@
 package jwt
 
 import (
+	"context"
+
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/roysitumorang/laukpauk/helper"
 	"github.com/roysitumorang/laukpauk/keys"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
+	"go.uber.org/zap"
 )
 
-func NewJWT() func(*fiber.Ctx) error {
-	privateKey, _ := keys.InitPrivateKey()
+func NewJWT(authUseCase authUseCase.AuthUseCase) func(*fiber.Ctx) error {
+	ctxt := "MiddlewareJWT-NewJWT"
 	return jwtware.New(
 		jwtware.Config{
-			SigningKey: jwtware.SigningKey{
-				JWTAlg: jwtware.RS256,
-				Key:    privateKey.Public(),
+			KeyFunc: func(token *jwt.Token) (interface{}, error) {
+				if token.Method.Alg() != jwtware.RS256 {
+					return nil, jwtware.ErrJWTAlg
+				}
+				keyID, _ := token.Header["kid"].(string)
+				return keys.GetVerificationKey(keyID)
+			},
+			Claims: &authModel.TokenClaims{},
+			SuccessHandler: func(c *fiber.Ctx) error {
+				ctx := context.Background()
+				token := c.Locals("user").(*jwt.Token)
+				claims := token.Claims.(*authModel.TokenClaims)
+				if claims.ID == "" {
+					return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+				}
+				revoked, err := authUseCase.IsTokenRevoked(ctx, claims.ID, claims.SessionID)
+				if err != nil {
+					helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIsTokenRevoked")
+					return helper.NewResponse(fiber.StatusInternalServerError, err.Error(), nil).WriteResponse(c)
+				}
+				if revoked {
+					return helper.NewResponse(fiber.StatusUnauthorized, "token has been revoked", nil).WriteResponse(c)
+				}
+				return c.Next()
 			},
 			ErrorHandler: func(ctx *fiber.Ctx, err error) error {
 				return helper.NewResponse(fiber.StatusUnauthorized, err.Error(), nil).WriteResponse(ctx)
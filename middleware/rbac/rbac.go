@@ -0,0 +1,77 @@
+package rbac
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+)
+
+// NewRBAC must be mounted after middleware/jwt, it only lets through active users
+// having one of roleIDs and stores their *authModel.CurrentUser in the request locals.
+func NewRBAC(roleIDs ...int64) func(*fiber.Ctx) error {
+	return newRBAC(false, roleIDs)
+}
+
+// NewTOTPEnrolmentRBAC is NewRBAC for the few routes admins may use while their
+// mandatory TOTP enrolment is still pending.
+func NewTOTPEnrolmentRBAC(roleIDs ...int64) func(*fiber.Ctx) error {
+	return newRBAC(true, roleIDs)
+}
+
+func newRBAC(allowTOTPPending bool, roleIDs []int64) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		token, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+		}
+		claims, ok := token.Claims.(*authModel.TokenClaims)
+		if !ok || claims.UserID < 1 || claims.Status != userModel.StatusActive {
+			return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+		}
+		if !slices.Contains(roleIDs, claims.RoleID) {
+			return helper.NewResponse(fiber.StatusForbidden, "forbidden", nil).WriteResponse(c)
+		}
+		if claims.TOTPPending && !allowTOTPPending {
+			return helper.NewResponse(fiber.StatusForbidden, "totp enrolment required", nil).WriteResponse(c)
+		}
+		currentUser := authModel.CurrentUser{
+			ID:        claims.UserID,
+			RoleID:    claims.RoleID,
+			Status:    claims.Status,
+			TokenID:   claims.ID,
+			SessionID: claims.SessionID,
+
+			TOTPPending:    claims.TOTPPending,
+			ImpersonatorID: claims.ImpersonatorID,
+		}
+		if claims.ExpiresAt != nil {
+			currentUser.ExpiresAt = claims.ExpiresAt.Time
+		}
+		c.Locals(authModel.CurrentUserContextKey, &currentUser)
+		return c.Next()
+	}
+}
+
+// NewNoImpersonation must be mounted after NewRBAC, it keeps impersonating admins away from
+// routes that would change the credentials of the user they act as.
+func NewNoImpersonation() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		currentUser, ok := GetCurrentUser(c)
+		if !ok {
+			return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+		}
+		if currentUser.ImpersonatorID != 0 {
+			return helper.NewResponse(fiber.StatusForbidden, authModel.ErrImpersonating.Error(), nil).WriteResponse(c)
+		}
+		return c.Next()
+	}
+}
+
+func GetCurrentUser(c *fiber.Ctx) (*authModel.CurrentUser, bool) {
+	currentUser, ok := c.Locals(authModel.CurrentUserContextKey).(*authModel.CurrentUser)
+	return currentUser, ok
+}
@@ -0,0 +1,62 @@
+package scope
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	oauthModel "github.com/roysitumorang/laukpauk/modules/oauth/model"
+	oauthUseCase "github.com/roysitumorang/laukpauk/modules/oauth/usecase"
+	"go.uber.org/zap"
+)
+
+// NewScope must be mounted after middleware/jwt, it only lets through client credentials tokens
+// of partner services still registered and granted every one of scopes, and stores their
+// *oauthModel.CurrentClient in the request locals. User tokens are refused, see middleware/rbac.
+func NewScope(oauthUseCase oauthUseCase.OAuthUseCase, scopes ...string) func(*fiber.Ctx) error {
+	ctxt := "MiddlewareScope-NewScope"
+	return func(c *fiber.Ctx) error {
+		ctx := context.Background()
+		token, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+		}
+		claims, ok := token.Claims.(*authModel.TokenClaims)
+		if !ok || claims.ClientID == "" {
+			return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+		}
+		// tokens outlive a revocation otherwise
+		client, err := oauthUseCase.FindActiveClient(ctx, claims.ClientID)
+		if err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveClient")
+			return helper.NewResponse(fiber.StatusInternalServerError, err.Error(), nil).WriteResponse(c)
+		}
+		if client == nil {
+			return helper.NewResponse(fiber.StatusUnauthorized, oauthModel.ErrInvalidClient.Error(), nil).WriteResponse(c)
+		}
+		// scopes removed from the client since the token was issued no longer count
+		grantedScopes := strings.Fields(claims.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(grantedScopes, scope) || !slices.Contains(client.Scopes, scope) {
+				return helper.NewResponse(fiber.StatusForbidden, "token lacks scope "+scope, nil).WriteResponse(c)
+			}
+		}
+		c.Locals(oauthModel.CurrentClientContextKey, &oauthModel.CurrentClient{
+			ID:       client.ID,
+			ClientID: client.ClientID,
+			Name:     client.Name,
+			Scopes:   grantedScopes,
+			TokenID:  claims.ID,
+		})
+		return c.Next()
+	}
+}
+
+func GetCurrentClient(c *fiber.Ctx) (*oauthModel.CurrentClient, bool) {
+	currentClient, ok := c.Locals(oauthModel.CurrentClientContextKey).(*oauthModel.CurrentClient)
+	return currentClient, ok
+}
@@ -0,0 +1,96 @@
+package scope
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	oauthModel "github.com/roysitumorang/laukpauk/modules/oauth/model"
+	oauthQuery "github.com/roysitumorang/laukpauk/modules/oauth/query"
+	oauthUseCase "github.com/roysitumorang/laukpauk/modules/oauth/usecase"
+)
+
+type (
+	// fakeOAuthQuery honours the client id and active filters
+	fakeOAuthQuery struct {
+		oauthQuery.OAuthQuery
+		clients []oauthModel.Client
+	}
+)
+
+func (q fakeOAuthQuery) FindClients(_ context.Context, filter oauthModel.ClientFilter) (response []oauthModel.Client, err error) {
+	for _, client := range q.clients {
+		if slices.Contains(filter.ClientIDs, client.ClientID) && (!filter.Active || client.RevokedAt == nil) {
+			response = append(response, client)
+		}
+	}
+	return
+}
+
+func TestNewScope(t *testing.T) {
+	revokedAt := time.Now()
+	useCase := oauthUseCase.NewOAuthUseCase(
+		fakeOAuthQuery{
+			clients: []oauthModel.Client{
+				{
+					ID:       1,
+					ClientID: "active",
+					Scopes:   []string{oauthModel.ScopeOrdersRead},
+				},
+				{
+					ID:        2,
+					ClientID:  "revoked",
+					Scopes:    []string{oauthModel.ScopeOrdersRead},
+					RevokedAt: &revokedAt,
+				},
+			},
+		},
+	)
+	for _, tc := range []struct {
+		name       string
+		claims     *authModel.TokenClaims
+		scopes     []string
+		statusCode int
+	}{
+		{"no token", nil, nil, fiber.StatusUnauthorized},
+		{"user token", &authModel.TokenClaims{UserID: 10}, nil, fiber.StatusUnauthorized},
+		{"unknown client", &authModel.TokenClaims{ClientID: "unknown", Scope: oauthModel.ScopeOrdersRead}, nil, fiber.StatusUnauthorized},
+		{"revoked client", &authModel.TokenClaims{ClientID: "revoked", Scope: oauthModel.ScopeOrdersRead}, nil, fiber.StatusUnauthorized},
+		{"scope not in token", &authModel.TokenClaims{ClientID: "active", Scope: oauthModel.ScopeOrdersRead}, []string{oauthModel.ScopeReportsRead}, fiber.StatusForbidden},
+		{"scope removed from client", &authModel.TokenClaims{ClientID: "active", Scope: oauthModel.ScopeOrdersRead + " " + oauthModel.ScopeOrdersWrite}, []string{oauthModel.ScopeOrdersWrite}, fiber.StatusForbidden},
+		{"granted scope", &authModel.TokenClaims{ClientID: "active", Scope: oauthModel.ScopeOrdersRead}, []string{oauthModel.ScopeOrdersRead}, fiber.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get(
+				"/",
+				// stands in for middleware/jwt, which stores the verified token under "user"
+				func(c *fiber.Ctx) error {
+					if tc.claims != nil {
+						c.Locals("user", &jwt.Token{Claims: tc.claims})
+					}
+					return c.Next()
+				},
+				NewScope(useCase, tc.scopes...),
+				func(c *fiber.Ctx) error {
+					if currentClient, ok := GetCurrentClient(c); !ok || currentClient.ClientID != "active" {
+						t.Errorf("current client = %+v, want active", currentClient)
+					}
+					return c.SendStatus(fiber.StatusOK)
+				},
+			)
+			response, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != tc.statusCode {
+				t.Errorf("status = %d, want %d", response.StatusCode, tc.statusCode)
+			}
+		})
+	}
+}
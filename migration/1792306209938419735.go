@@ -0,0 +1,25 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792306209938419735] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`ALTER TABLE users
+				ALTER COLUMN password_reset_token TYPE char varying
+				, ADD COLUMN password_reset_token_expires_at timestamp with time zone;`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON users (password_reset_token);`,
+		)
+		return
+	}
+}
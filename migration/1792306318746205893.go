@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792306318746205893] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE sessions (
+				id bigint NOT NULL PRIMARY KEY
+				, user_id bigint NOT NULL REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+				, expires_at timestamp with time zone NOT NULL
+				, revoked_at timestamp with time zone
+				, created_at timestamp with time zone NOT NULL
+				, updated_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON sessions (user_id);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE refresh_tokens (
+				id bigint NOT NULL PRIMARY KEY
+				, session_id bigint NOT NULL REFERENCES sessions (id) ON UPDATE CASCADE ON DELETE CASCADE
+				, token_hash char varying NOT NULL UNIQUE
+				, expires_at timestamp with time zone NOT NULL
+				, used_at timestamp with time zone
+				, created_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON refresh_tokens (session_id);`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE TABLE revoked_tokens (
+				jti char varying NOT NULL PRIMARY KEY
+				, expires_at timestamp with time zone NOT NULL
+				, created_at timestamp with time zone NOT NULL
+			);`,
+		)
+		return
+	}
+}
@@ -0,0 +1,22 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792306545581666979] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		_, err = tx.Exec(
+			ctx,
+			`CREATE TABLE login_failures (
+				key char varying NOT NULL PRIMARY KEY
+				, failed_count integer NOT NULL
+				, last_failed_at timestamp with time zone NOT NULL
+				, locked_until timestamp with time zone
+			);`,
+		)
+		return
+	}
+}
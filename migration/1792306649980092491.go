@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792306649980092491] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE one_time_passwords (
+				id bigint NOT NULL PRIMARY KEY
+				, user_id bigint NOT NULL REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+				, purpose char varying NOT NULL
+				, recipient char varying NOT NULL
+				, code_hash char varying NOT NULL
+				, attempts integer NOT NULL DEFAULT 0
+				, expires_at timestamp with time zone NOT NULL
+				, consumed_at timestamp with time zone
+				, created_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON one_time_passwords (user_id, purpose, created_at);`,
+		)
+		return
+	}
+}
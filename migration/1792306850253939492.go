@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792306850253939492] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE api_keys (
+				id bigint NOT NULL PRIMARY KEY
+				, user_id bigint NOT NULL REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+				, name char varying NOT NULL
+				, prefix char varying NOT NULL
+				, key_hash char varying NOT NULL UNIQUE
+				, scopes char varying[] NOT NULL
+				, last_used_at timestamp with time zone
+				, revoked_at timestamp with time zone
+				, created_at timestamp with time zone NOT NULL
+				, updated_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON api_keys (user_id);`,
+		); err != nil {
+			return
+		}
+		// carry over the plain text keys of sellers as read only keys, then stop storing them in clear
+		if _, err = tx.Exec(
+			ctx,
+			`INSERT INTO api_keys (
+				id
+				, user_id
+				, name
+				, prefix
+				, key_hash
+				, scopes
+				, created_at
+				, updated_at
+			)
+			SELECT
+				id
+				, id
+				, 'legacy'
+				, LEFT(api_key, 4)
+				, ENCODE(SHA256(CONVERT_TO(api_key, 'UTF8')), 'hex')
+				, ARRAY['products:read', 'orders:read']
+				, NOW()
+				, NOW()
+			FROM users
+			WHERE role_id = 3
+			AND api_key IS NOT NULL
+			AND api_key <> ''
+			ON CONFLICT (key_hash) DO NOTHING`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE users SET api_key = NULL WHERE api_key IS NOT NULL`,
+		)
+		return
+	}
+}
@@ -0,0 +1,19 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792306946055411024] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		_, err = tx.Exec(
+			ctx,
+			`ALTER TABLE users
+				ADD COLUMN suspended_at timestamp with time zone
+				, ADD COLUMN suspension_reason char varying;`,
+		)
+		return
+	}
+}
@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792307017402302036] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`ALTER TABLE users ADD COLUMN activation_token_expires_at timestamp with time zone;`,
+		); err != nil {
+			return
+		}
+		// give registrations pending before this change a day to complete instead of expiring them at once
+		if _, err = tx.Exec(
+			ctx,
+			`UPDATE users SET
+				activation_token_expires_at = NOW() + INTERVAL '1 day'
+			WHERE activation_token IS NOT NULL`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON one_time_passwords (recipient, created_at);`,
+		)
+		return
+	}
+}
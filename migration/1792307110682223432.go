@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792307110682223432] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE user_totps (
+				user_id bigint NOT NULL PRIMARY KEY REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+				, secret char varying NOT NULL
+				, confirmed_at timestamp with time zone
+				, last_used_step bigint
+				, created_at timestamp with time zone NOT NULL
+				, updated_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE totp_recovery_codes (
+				id bigint NOT NULL PRIMARY KEY
+				, user_id bigint NOT NULL REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+				, code_hash char varying NOT NULL
+				, used_at timestamp with time zone
+				, created_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE UNIQUE INDEX ON totp_recovery_codes (user_id, code_hash);`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE TABLE settings (
+				key char varying NOT NULL PRIMARY KEY
+				, value char varying NOT NULL
+				, updated_by bigint
+				, updated_at timestamp with time zone NOT NULL
+			);`,
+		)
+		return
+	}
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313104882305117] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`ALTER TABLE sessions
+				ADD COLUMN device char varying
+				, ADD COLUMN ip_address inet
+				, ADD COLUMN user_agent char varying
+				, ADD COLUMN last_seen_at timestamp with time zone;`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`UPDATE sessions SET last_seen_at = updated_at`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`ALTER TABLE sessions ALTER COLUMN last_seen_at SET NOT NULL;`,
+		)
+		return
+	}
+}
@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313262519047386] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE TABLE audit_logs (
+				id bigint NOT NULL PRIMARY KEY
+				, user_id bigint REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL
+				, actor_id bigint REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL
+				, action char varying NOT NULL
+				, data jsonb NOT NULL DEFAULT '{}'
+				, ip_address inet
+				, created_at timestamp with time zone NOT NULL
+			);`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON audit_logs (user_id, created_at);`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON audit_logs (action, created_at);`,
+		)
+		return
+	}
+}
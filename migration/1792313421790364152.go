@@ -0,0 +1,24 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313421790364152] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`ALTER TABLE users ADD COLUMN email_verified_at timestamp with time zone;`,
+		); err != nil {
+			return
+		}
+		// legacy emails were never verified, so only emails verified from now on have to be unique
+		_, err = tx.Exec(
+			ctx,
+			`CREATE UNIQUE INDEX users_role_id_email_idx ON users (role_id, email) WHERE email_verified_at IS NOT NULL;`,
+		)
+		return
+	}
+}
@@ -0,0 +1,18 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313497265130842] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		_, err = tx.Exec(
+			ctx,
+			`ALTER TABLE sessions
+				ADD COLUMN impersonated_by bigint REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE;`,
+		)
+		return
+	}
+}
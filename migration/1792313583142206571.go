@@ -0,0 +1,25 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313583142206571] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		if _, err = tx.Exec(
+			ctx,
+			`ALTER TABLE users
+				ADD COLUMN deletion_requested_at timestamp with time zone
+				, ADD COLUMN deleted_at timestamp with time zone;`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE INDEX ON users (deletion_requested_at) WHERE deleted_at IS NULL;`,
+		)
+		return
+	}
+}
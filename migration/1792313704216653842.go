@@ -0,0 +1,28 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313704216653842] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		_, err = tx.Exec(
+			ctx,
+			`CREATE TABLE oauth_clients (
+				id bigint NOT NULL PRIMARY KEY
+				, client_id char varying NOT NULL UNIQUE
+				, name char varying NOT NULL
+				, secret_hash char varying NOT NULL
+				, scopes char varying[] NOT NULL
+				, last_used_at timestamp with time zone
+				, revoked_at timestamp with time zone
+				, created_by bigint REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL
+				, created_at timestamp with time zone NOT NULL
+				, updated_at timestamp with time zone NOT NULL
+			);`,
+		)
+		return
+	}
+}
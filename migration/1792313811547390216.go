@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313811547390216] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		// coverage_area predates the unique constraint bulk upserts rely on, drop duplicates first
+		if _, err = tx.Exec(
+			ctx,
+			`DELETE FROM coverage_area a
+			USING coverage_area b
+			WHERE a.ctid > b.ctid
+			AND a.user_id = b.user_id
+			AND a.village_id = b.village_id;`,
+		); err != nil {
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`ALTER TABLE coverage_area
+				ADD COLUMN IF NOT EXISTS delivery_fee integer
+				, ADD COLUMN IF NOT EXISTS created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+				, ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP;`,
+		); err != nil {
+			return
+		}
+		_, err = tx.Exec(
+			ctx,
+			`CREATE UNIQUE INDEX IF NOT EXISTS coverage_area_user_id_village_id_idx ON coverage_area (user_id, village_id);`,
+		)
+		return
+	}
+}
@@ -0,0 +1,17 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	Migrations[1792313926380512774] = func(ctx context.Context, tx pgx.Tx) (err error) {
+		_, err = tx.Exec(
+			ctx,
+			`CREATE INDEX IF NOT EXISTS users_latitude_longitude_idx ON users (latitude, longitude) WHERE latitude IS NOT NULL AND longitude IS NOT NULL;`,
+		)
+		return
+	}
+}
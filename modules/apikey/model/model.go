@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/errors"
+)
+
+const (
+	KeyPrefix          = "lpk_"
+	MaxActiveApiKeys   = 10
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+)
+
+var (
+	ErrInvalidApiKey = errors.New(fiber.StatusUnauthorized, "invalid api key")
+
+	Scopes = []string{
+		ScopeProductsRead,
+		ScopeProductsWrite,
+		ScopeOrdersRead,
+		ScopeOrdersWrite,
+	}
+)
+
+type (
+	ApiKey struct {
+		ID         int64      `json:"id"`
+		UserID     int64      `json:"user_id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"-"`
+		MaskedKey  string     `json:"masked_key"`
+		Scopes     []string   `json:"scopes"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		RevokedAt  *time.Time `json:"revoked_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+		UpdatedAt  time.Time  `json:"updated_at"`
+	}
+
+	PingResponse struct {
+		SellerID int64    `json:"seller_id"`
+		Scopes   []string `json:"scopes"`
+	}
+
+	ApiKeyFilter struct {
+		ApiKeyIDs,
+		UserIDs []int64
+		KeyHashes []string
+		Active    bool
+	}
+
+	CreateApiKeyRequest struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+
+	// CreateApiKeyResponse is the only place the plain key is ever returned, only its hash is stored
+	CreateApiKeyResponse struct {
+		ApiKey
+		Key string `json:"key"`
+	}
+)
@@ -0,0 +1,119 @@
+package presenter
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	middlewareApiKey "github.com/roysitumorang/laukpauk/middleware/apikey"
+	middlewareJWT "github.com/roysitumorang/laukpauk/middleware/jwt"
+	middlewareRBAC "github.com/roysitumorang/laukpauk/middleware/rbac"
+	"github.com/roysitumorang/laukpauk/modules/apikey/model"
+	"github.com/roysitumorang/laukpauk/modules/apikey/sanitizer"
+	apiKeyUseCase "github.com/roysitumorang/laukpauk/modules/apikey/usecase"
+	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	"go.uber.org/zap"
+)
+
+type (
+	apiKeyHTTPHandler struct {
+		authUseCase   authUseCase.AuthUseCase
+		apiKeyUseCase apiKeyUseCase.ApiKeyUseCase
+	}
+)
+
+func NewApiKeyHTTPHandler(
+	authUseCase authUseCase.AuthUseCase,
+	apiKeyUseCase apiKeyUseCase.ApiKeyUseCase,
+) *apiKeyHTTPHandler {
+	return &apiKeyHTTPHandler{
+		authUseCase:   authUseCase,
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+func (q *apiKeyHTTPHandler) Mount(r fiber.Router) {
+	r.Use(middlewareJWT.NewJWT(q.authUseCase), middlewareRBAC.NewRBAC(roleModel.RoleSeller)).
+		Get("", q.FindApiKeys).
+		Post("", q.CreateApiKey).
+		Delete("/:api_key_id", q.RevokeApiKey)
+}
+
+// MountIntegration serves seller integrations, they authenticate with X-API-Key instead of a user token
+func (q *apiKeyHTTPHandler) MountIntegration(r fiber.Router) {
+	r.Use(middlewareApiKey.NewApiKey(q.apiKeyUseCase)).
+		Get("/ping", q.Ping)
+}
+
+func (q *apiKeyHTTPHandler) FindApiKeys(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "ApiKeyPresenter-FindApiKeys"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.apiKeyUseCase.FindApiKeys(
+		ctx,
+		model.ApiKeyFilter{
+			UserIDs: []int64{currentUser.ID},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindApiKeys")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *apiKeyHTTPHandler) CreateApiKey(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "ApiKeyPresenter-CreateApiKey"
+	request, statusCode, err := sanitizer.CreateApiKey(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateApiKey")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.apiKeyUseCase.CreateApiKey(ctx, currentUser.ID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateApiKey")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusCreated, "", response).WriteResponse(c)
+}
+
+func (q *apiKeyHTTPHandler) RevokeApiKey(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "ApiKeyPresenter-RevokeApiKey"
+	apiKeyID, _ := strconv.ParseInt(c.Params("api_key_id"), 10, 64)
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	if err := q.apiKeyUseCase.RevokeApiKey(ctx, currentUser.ID, apiKeyID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeApiKey")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+// Ping lets POS software check its key, it answers with the seller and scopes the key resolves to
+func (q *apiKeyHTTPHandler) Ping(c *fiber.Ctx) error {
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	return helper.NewResponse(
+		fiber.StatusOK,
+		"",
+		model.PingResponse{
+			SellerID: currentUser.ID,
+			Scopes:   currentUser.Scopes,
+		},
+	).WriteResponse(c)
+}
@@ -0,0 +1,202 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/apikey/model"
+	"go.uber.org/zap"
+)
+
+const (
+	maskedKeySuffix = "********"
+)
+
+type (
+	apiKeyQuery struct {
+		dbRead, dbWrite *pgxpool.Pool
+	}
+)
+
+func NewApiKeyQuery(
+	dbRead,
+	dbWrite *pgxpool.Pool,
+) ApiKeyQuery {
+	return &apiKeyQuery{
+		dbRead:  dbRead,
+		dbWrite: dbWrite,
+	}
+}
+
+func (q *apiKeyQuery) FindApiKeys(ctx context.Context, filter model.ApiKeyFilter) (response []model.ApiKey, err error) {
+	ctxt := "ApiKeyQuery-FindApiKeys"
+	var (
+		params     []interface{}
+		conditions []string
+	)
+	if n := len(filter.ApiKeyIDs); n > 0 {
+		placeholders := make([]string, n)
+		for i, apiKeyID := range filter.ApiKeyIDs {
+			params = append(params, apiKeyID)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		conditions = append(conditions, fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if n := len(filter.UserIDs); n > 0 {
+		placeholders := make([]string, n)
+		for i, userID := range filter.UserIDs {
+			params = append(params, userID)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		conditions = append(conditions, fmt.Sprintf("user_id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if n := len(filter.KeyHashes); n > 0 {
+		placeholders := make([]string, n)
+		for i, keyHash := range filter.KeyHashes {
+			params = append(params, keyHash)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		conditions = append(conditions, fmt.Sprintf("key_hash IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if filter.Active {
+		conditions = append(conditions, "revoked_at IS NULL")
+	}
+	query := `SELECT
+			id
+			, user_id
+			, name
+			, prefix
+			, scopes
+			, last_used_at
+			, revoked_at
+			, created_at
+			, updated_at
+		FROM api_keys`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY -id"
+	// revocations must take effect immediately, so keys are never read from the replica
+	rows, err := q.dbWrite.Query(ctx, query, params...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var apiKey model.ApiKey
+		if err = rows.Scan(
+			&apiKey.ID,
+			&apiKey.UserID,
+			&apiKey.Name,
+			&apiKey.Prefix,
+			&apiKey.Scopes,
+			&apiKey.LastUsedAt,
+			&apiKey.RevokedAt,
+			&apiKey.CreatedAt,
+			&apiKey.UpdatedAt,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		apiKey.MaskedKey = apiKey.Prefix + maskedKeySuffix
+		response = append(response, apiKey)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+func (q *apiKeyQuery) CreateApiKey(ctx context.Context, userID int64, request model.CreateApiKeyRequest, prefix, keyHash string) (*model.ApiKey, error) {
+	ctxt := "ApiKeyQuery-CreateApiKey"
+	apiKeyID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	now := time.Now().UTC()
+	response := model.ApiKey{
+		ID:        apiKeyID,
+		UserID:    userID,
+		Name:      request.Name,
+		Prefix:    prefix,
+		MaskedKey: prefix + maskedKeySuffix,
+		Scopes:    request.Scopes,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO api_keys (
+			id
+			, user_id
+			, name
+			, prefix
+			, key_hash
+			, scopes
+			, created_at
+			, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`,
+		response.ID,
+		response.UserID,
+		response.Name,
+		response.Prefix,
+		keyHash,
+		response.Scopes,
+		now,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *apiKeyQuery) RevokeApiKey(ctx context.Context, userID, apiKeyID int64) (response int64, err error) {
+	ctxt := "ApiKeyQuery-RevokeApiKey"
+	now := time.Now().UTC()
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE api_keys SET
+			revoked_at = $1
+			, updated_at = $1
+		WHERE id = $2
+		AND user_id = $3
+		AND revoked_at IS NULL
+		RETURNING id`,
+		now,
+		apiKeyID,
+		userID,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *apiKeyQuery) TouchApiKey(ctx context.Context, apiKeyID int64) (err error) {
+	ctxt := "ApiKeyQuery-TouchApiKey"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE api_keys SET
+			last_used_at = $1
+		WHERE id = $2`,
+		time.Now().UTC(),
+		apiKeyID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
@@ -0,0 +1,16 @@
+package query
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/modules/apikey/model"
+)
+
+type (
+	ApiKeyQuery interface {
+		FindApiKeys(ctx context.Context, filter model.ApiKeyFilter) (response []model.ApiKey, err error)
+		CreateApiKey(ctx context.Context, userID int64, request model.CreateApiKeyRequest, prefix, keyHash string) (response *model.ApiKey, err error)
+		RevokeApiKey(ctx context.Context, userID, apiKeyID int64) (response int64, err error)
+		TouchApiKey(ctx context.Context, apiKeyID int64) (err error)
+	}
+)
@@ -0,0 +1,48 @@
+package sanitizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/apikey/model"
+	"go.uber.org/zap"
+)
+
+func CreateApiKey(ctx context.Context, c *fiber.Ctx) (request model.CreateApiKeyRequest, statusCode int, err error) {
+	ctxt := "ApiKeySanitizer-CreateApiKey"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.Name = strings.TrimSpace(request.Name); request.Name == "" {
+		err = errors.New("name is required")
+		return
+	}
+	if len(request.Scopes) == 0 {
+		err = errors.New("scopes is required")
+		return
+	}
+	scopes := make([]string, 0, len(request.Scopes))
+	for _, scope := range request.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !slices.Contains(model.Scopes, scope) {
+			err = fmt.Errorf("invalid scope %s, valid scopes are %s", scope, strings.Join(model.Scopes, ", "))
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	request.Scopes = scopes
+	statusCode = fiber.StatusOK
+	return
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/apikey/model"
+	apiKeyQuery "github.com/roysitumorang/laukpauk/modules/apikey/query"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	userQuery "github.com/roysitumorang/laukpauk/modules/user/query"
+	"go.uber.org/zap"
+)
+
+const (
+	keyLength          = 40
+	keyVisibleLength   = 8
+	lastUsedAtInterval = time.Minute
+)
+
+type (
+	apiKeyUseCaseImplementation struct {
+		apiKeyQuery apiKeyQuery.ApiKeyQuery
+		userQuery   userQuery.UserQuery
+	}
+)
+
+func NewApiKeyUseCase(
+	apiKeyQuery apiKeyQuery.ApiKeyQuery,
+	userQuery userQuery.UserQuery,
+) ApiKeyUseCase {
+	return &apiKeyUseCaseImplementation{
+		apiKeyQuery: apiKeyQuery,
+		userQuery:   userQuery,
+	}
+}
+
+func (q *apiKeyUseCaseImplementation) FindApiKeys(ctx context.Context, filter model.ApiKeyFilter) (response []model.ApiKey, err error) {
+	ctxt := "ApiKeyUseCase-FindApiKeys"
+	if response, err = q.apiKeyQuery.FindApiKeys(ctx, filter); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindApiKeys")
+	}
+	return
+}
+
+func (q *apiKeyUseCaseImplementation) CreateApiKey(ctx context.Context, userID int64, request model.CreateApiKeyRequest) (response *model.CreateApiKeyResponse, err error) {
+	ctxt := "ApiKeyUseCase-CreateApiKey"
+	apiKeys, err := q.apiKeyQuery.FindApiKeys(
+		ctx,
+		model.ApiKeyFilter{
+			UserIDs: []int64{userID},
+			Active:  true,
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindApiKeys")
+		return
+	}
+	if len(apiKeys) >= model.MaxActiveApiKeys {
+		err = fmt.Errorf("at most %d active api keys are allowed, revoke an unused one first", model.MaxActiveApiKeys)
+		return
+	}
+	key := model.KeyPrefix + helper.GenerateRandomString(keyLength)
+	apiKey, err := q.apiKeyQuery.CreateApiKey(ctx, userID, request, key[:len(model.KeyPrefix)+keyVisibleLength], helper.HashToken(key))
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateApiKey")
+		return
+	}
+	response = &model.CreateApiKeyResponse{
+		ApiKey: *apiKey,
+		Key:    key,
+	}
+	return
+}
+
+func (q *apiKeyUseCaseImplementation) RevokeApiKey(ctx context.Context, userID, apiKeyID int64) (err error) {
+	ctxt := "ApiKeyUseCase-RevokeApiKey"
+	revokedApiKeyID, err := q.apiKeyQuery.RevokeApiKey(ctx, userID, apiKeyID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeApiKey")
+		return
+	}
+	if revokedApiKeyID == 0 {
+		err = errors.New("api key not found")
+	}
+	return
+}
+
+// Authenticate resolves a plain api key into its owning seller, who must still be active.
+func (q *apiKeyUseCaseImplementation) Authenticate(ctx context.Context, key string) (response *authModel.CurrentUser, err error) {
+	ctxt := "ApiKeyUseCase-Authenticate"
+	apiKeys, err := q.apiKeyQuery.FindApiKeys(
+		ctx,
+		model.ApiKeyFilter{
+			KeyHashes: []string{helper.HashToken(key)},
+			Active:    true,
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindApiKeys")
+		return
+	}
+	if len(apiKeys) == 0 {
+		err = model.ErrInvalidApiKey
+		return
+	}
+	apiKey := apiKeys[0]
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{apiKey.UserID},
+			RoleIDs: []int64{roleModel.RoleSeller},
+			Status:  []int{userModel.StatusActive},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = model.ErrInvalidApiKey
+		return
+	}
+	// POS software may poll every few seconds, a minute resolution is plenty for last_used_at
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > lastUsedAtInterval {
+		if err = q.apiKeyQuery.TouchApiKey(ctx, apiKey.ID); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrTouchApiKey")
+			return
+		}
+	}
+	user := users[0]
+	response = &authModel.CurrentUser{
+		ID:       user.ID,
+		RoleID:   user.Role.ID,
+		Status:   user.Status,
+		ApiKeyID: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}
+	return
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/modules/apikey/model"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+)
+
+type (
+	ApiKeyUseCase interface {
+		FindApiKeys(ctx context.Context, filter model.ApiKeyFilter) (response []model.ApiKey, err error)
+		CreateApiKey(ctx context.Context, userID int64, request model.CreateApiKeyRequest) (response *model.CreateApiKeyResponse, err error)
+		RevokeApiKey(ctx context.Context, userID, apiKeyID int64) (err error)
+		Authenticate(ctx context.Context, key string) (response *authModel.CurrentUser, err error)
+	}
+)
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	ActionMobilePhoneChanged = "user.mobile_phone_changed"
+	ActionEmailChanged       = "user.email_changed"
+	ActionImpersonationStart = "user.impersonation_started"
+	ActionImpersonationEnd   = "user.impersonation_ended"
+)
+
+type (
+	AuditLog struct {
+		ID        int64                  `json:"id"`
+		UserID    int64                  `json:"user_id"`
+		ActorID   int64                  `json:"actor_id"`
+		Action    string                 `json:"action"`
+		Data      map[string]interface{} `json:"data"`
+		IpAddress net.IP                 `json:"ip_address"`
+		CreatedAt time.Time              `json:"created_at"`
+	}
+)
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/audit/model"
+	"go.uber.org/zap"
+)
+
+type (
+	auditQuery struct {
+		dbRead, dbWrite *pgxpool.Pool
+	}
+)
+
+func NewAuditQuery(
+	dbRead,
+	dbWrite *pgxpool.Pool,
+) AuditQuery {
+	return &auditQuery{
+		dbRead:  dbRead,
+		dbWrite: dbWrite,
+	}
+}
+
+func (q *auditQuery) CreateAuditLog(ctx context.Context, request model.AuditLog) (*model.AuditLog, error) {
+	ctxt := "AuditQuery-CreateAuditLog"
+	data, err := json.Marshal(request.Data)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrMarshal")
+		return nil, err
+	}
+	auditLogID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	response := request
+	response.ID = auditLogID
+	response.CreatedAt = time.Now().UTC()
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO audit_logs (
+			id
+			, user_id
+			, actor_id
+			, action
+			, data
+			, ip_address
+			, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		response.ID,
+		response.UserID,
+		response.ActorID,
+		response.Action,
+		data,
+		response.IpAddress,
+		response.CreatedAt,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return nil, err
+	}
+	return &response, nil
+}
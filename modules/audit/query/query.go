@@ -0,0 +1,13 @@
+package query
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/modules/audit/model"
+)
+
+type (
+	AuditQuery interface {
+		CreateAuditLog(ctx context.Context, request model.AuditLog) (response *model.AuditLog, err error)
+	}
+)
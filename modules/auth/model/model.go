@@ -1,28 +1,149 @@
 package model
 
 import (
+	"fmt"
+	"math"
 	"net"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/roysitumorang/laukpauk/errors"
 	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
 )
 
+const (
+	CurrentUserContextKey = "current_user"
+
+	OTPPurposeActivation        = "activation"
+	OTPPurposeMobilePhoneChange = "mobile_phone_change"
+	OTPPurposeEmailChange       = "email_change"
+	OTPPurposeLogin             = "login"
+
+	SettingAdminTOTPMandatory = "admin_totp_mandatory"
+)
+
 var (
 	ErrLoginFailed      = errors.New(fiber.StatusBadRequest, "login failed")
 	ErrActivationFailed = errors.New(fiber.StatusBadRequest, "activation failed")
+
+	ErrPasswordResetFailed = errors.New(fiber.StatusBadRequest, "password reset failed")
+	ErrInvalidRefreshToken = errors.New(fiber.StatusUnauthorized, "invalid refresh token")
+	ErrLoginLocked         = errors.New(fiber.StatusTooManyRequests, "too many failed login attempts, try again later")
+	ErrSessionNotFound     = errors.New(fiber.StatusNotFound, "session not found")
+	ErrImpersonating       = errors.New(fiber.StatusForbidden, "not allowed while impersonating")
+
+	ErrTOTPRequired    = errors.New(fiber.StatusUnauthorized, "totp code or recovery code is required")
+	ErrInvalidTOTP     = errors.New(fiber.StatusUnauthorized, "invalid totp code or recovery code")
+	ErrTOTPNotEnrolled = errors.New(fiber.StatusBadRequest, "totp is not enrolled")
+	ErrTOTPEnrolled    = errors.New(fiber.StatusBadRequest, "totp is already enrolled")
+	ErrTOTPMandatory   = errors.New(fiber.StatusForbidden, "totp is mandatory for admins and can't be disabled")
+
+	ErrInvalidOTP          = errors.New(fiber.StatusBadRequest, "invalid or expired otp")
+	ErrOTPAttemptsExceeded = errors.New(fiber.StatusTooManyRequests, "too many invalid otp attempts, request a new one")
 )
 
+func NewErrOTPResendTooSoon(retryIn time.Duration) error {
+	return errors.New(
+		fiber.StatusTooManyRequests,
+		fmt.Sprintf("otp already sent, retry in %d seconds", int64(math.Ceil(retryIn.Seconds()))),
+	)
+}
+
+func NewErrLoginThrottled(retryIn time.Duration) error {
+	return errors.New(
+		fiber.StatusTooManyRequests,
+		fmt.Sprintf("too many failed login attempts, retry in %d seconds", int64(math.Ceil(retryIn.Seconds()))),
+	)
+}
+
 type (
 	LoginRequest struct {
-		MobilePhone string `json:"mobile_phone"`
-		Password    string `json:"password"`
+		// MobilePhone also accepts a verified email
+		MobilePhone  string `json:"mobile_phone"`
+		Password     string `json:"password"`
+		TOTPCode     string `json:"totp_code"`
+		RecoveryCode string `json:"recovery_code"`
+		SessionClient
+	}
+
+	// SessionClient describes where a session was started or last refreshed from
+	SessionClient struct {
+		Device    string `json:"device"`
+		UserAgent string `json:"-"`
+		IpAddress net.IP `json:"-"`
+	}
+
+	LoginFailure struct {
+		Key          string
+		FailedCount  int
+		LastFailedAt time.Time
+		LockedUntil  *time.Time
 	}
 
 	LoginResponse struct {
-		IDToken   string         `json:"id_token"`
-		ExpiresIn int64          `json:"expires_in"`
-		Profile   userModel.User `json:"profile"`
+		IDToken               string         `json:"id_token"`
+		ExpiresIn             int64          `json:"expires_in"`
+		RefreshToken          string         `json:"refresh_token"`
+		RefreshTokenExpiresIn int64          `json:"refresh_token_expires_in"`
+		Profile               userModel.User `json:"profile"`
+	}
+
+	TokenClaims struct {
+		UserID    int64 `json:"id"`
+		RoleID    int64 `json:"role_id"`
+		Status    int   `json:"status"`
+		SessionID int64 `json:"sid,string"`
+		// TOTPPending marks admins who must enrol TOTP before doing anything else
+		TOTPPending bool `json:"totp_pending,omitempty"`
+		// ImpersonatorID is the SuperAdmin acting as this user, impersonation tokens have no session to refresh
+		ImpersonatorID int64 `json:"impersonator_id,omitempty"`
+		// ClientID and Scope are only set on client credentials tokens of partner services, which have no user
+		ClientID string `json:"client_id,omitempty"`
+		Scope    string `json:"scope,omitempty"`
+		jwt.RegisteredClaims
+	}
+
+	CurrentUser struct {
+		ID        int64
+		RoleID    int64
+		Status    int
+		TokenID   string
+		SessionID int64
+		ExpiresAt time.Time
+		ApiKeyID  int64
+		Scopes    []string
+
+		TOTPPending    bool
+		ImpersonatorID int64
+	}
+
+	RefreshTokenRequest struct {
+		RefreshToken string `json:"refresh_token"`
+		SessionClient
+	}
+
+	Session struct {
+		ID         int64      `json:"id"`
+		UserID     int64      `json:"user_id"`
+		Device     *string    `json:"device"`
+		IpAddress  net.IP     `json:"ip_address"`
+		UserAgent  *string    `json:"user_agent"`
+		Current    bool       `json:"current"`
+		LastSeenAt time.Time  `json:"last_seen_at"`
+		ExpiresAt  time.Time  `json:"expires_at"`
+		RevokedAt  *time.Time `json:"revoked_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+		UpdatedAt  time.Time  `json:"updated_at"`
+	}
+
+	RefreshToken struct {
+		ID        int64
+		SessionID int64
+		UserID    int64
+		ExpiresAt time.Time
+		UsedAt    *time.Time
+		Session   Session
 	}
 
 	ChangePassword struct {
@@ -30,6 +151,15 @@ type (
 		NewPassword string `json:"new_password"`
 	}
 
+	ForgotPasswordRequest struct {
+		MobilePhone string `json:"mobile_phone"`
+	}
+
+	ResetPasswordRequest struct {
+		PasswordResetToken string `json:"password_reset_token"`
+		NewPassword        string `json:"new_password"`
+	}
+
 	RegisterRequest struct {
 		RoleID        int64  `json:"-"`
 		Address       string `json:"address"`
@@ -39,9 +169,130 @@ type (
 		Password      string `json:"password"`
 		MobilePhone   string `json:"mobile_phone"`
 		IpAddress     net.IP `json:"-"`
+
+		ActivationTokenExpiresAt time.Time `json:"-"`
+	}
+
+	// UpdateProfileRequest replaces every field at once, omitted optional fields are cleared
+	UpdateProfileRequest struct {
+		Name          string     `json:"name"`
+		Address       string     `json:"address"`
+		VillageID     int64      `json:"village_id"`
+		SubdistrictID int64      `json:"-"`
+		Gender        *string    `json:"gender"`
+		DateOfBirth   string     `json:"date_of_birth"`
+		BirthDate     *time.Time `json:"-"`
+		Company       *string    `json:"company"`
 	}
 
 	RegisterResponse struct {
-		ActivationToken string `json:"activation_token"`
+		UserID          int64  `json:"-"`
+		ActivationToken string `json:"activation_token,omitempty"`
+		OTPExpiresIn    int64  `json:"otp_expires_in"`
+	}
+
+	ResendActivationResponse struct {
+		ActivationToken string `json:"activation_token,omitempty"`
+		OTPExpiresIn    int64  `json:"otp_expires_in"`
+	}
+
+	TOTP struct {
+		UserID       int64
+		Secret       string
+		ConfirmedAt  *time.Time
+		LastUsedStep *int64
+	}
+
+	TOTPEnrolment struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	}
+
+	TOTPCodeRequest struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	TOTPRecoveryCodes struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	TOTPPolicy struct {
+		Mandatory bool `json:"mandatory"`
+	}
+
+	ActivateRequest struct {
+		MobilePhone string `json:"mobile_phone"`
+		OTP         string `json:"otp"`
+		SessionClient
+	}
+
+	ResendActivationRequest struct {
+		MobilePhone string `json:"mobile_phone"`
+	}
+
+	// AccountExport is everything stored about a user, orders will join it once they exist
+	AccountExport struct {
+		Profile    userModel.User `json:"profile"`
+		Sessions   []Session      `json:"sessions"`
+		ExportedAt time.Time      `json:"exported_at"`
+	}
+
+	AccountDeletion struct {
+		DeletionRequestedAt time.Time `json:"deletion_requested_at"`
+		AnonymisedAfter     time.Time `json:"anonymised_after"`
+	}
+
+	LoginOTPRequest struct {
+		MobilePhone string `json:"mobile_phone"`
+		IpAddress   net.IP `json:"-"`
+	}
+
+	LoginOTPResponse struct {
+		OTPExpiresIn int64 `json:"otp_expires_in"`
+	}
+
+	VerifyLoginOTPRequest struct {
+		MobilePhone string `json:"mobile_phone"`
+		OTP         string `json:"otp"`
+		SessionClient
+	}
+
+	MobilePhoneChangeRequest struct {
+		MobilePhone string `json:"mobile_phone"`
+	}
+
+	MobilePhoneChangeResponse struct {
+		OTPExpiresIn int64 `json:"otp_expires_in"`
+	}
+
+	ConfirmMobilePhoneChangeRequest struct {
+		OTP       string `json:"otp"`
+		IpAddress net.IP `json:"-"`
+	}
+
+	EmailChangeRequest struct {
+		Email string `json:"email"`
+	}
+
+	EmailChangeResponse struct {
+		OTPExpiresIn int64 `json:"otp_expires_in"`
+	}
+
+	ConfirmEmailChangeRequest struct {
+		OTP       string `json:"otp"`
+		IpAddress net.IP `json:"-"`
+	}
+
+	OTP struct {
+		ID         int64
+		UserID     int64
+		Purpose    string
+		Recipient  string
+		CodeHash   string
+		Attempts   int
+		ExpiresAt  time.Time
+		ConsumedAt *time.Time
+		CreatedAt  time.Time
 	}
 )
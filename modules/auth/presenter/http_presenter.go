@@ -2,11 +2,13 @@ package presenter
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/config"
 	"github.com/roysitumorang/laukpauk/helper"
 	middlewareJWT "github.com/roysitumorang/laukpauk/middleware/jwt"
+	middlewareRBAC "github.com/roysitumorang/laukpauk/middleware/rbac"
 	"github.com/roysitumorang/laukpauk/modules/auth/sanitizer"
 	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
 	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
@@ -33,26 +35,88 @@ func NewAuthHTTPHandler(
 }
 
 func (q *authHTTPHandler) Mount(r fiber.Router) {
-	bearerVerifier := middlewareJWT.NewJWT()
+	bearerVerifier := middlewareJWT.NewJWT(q.authUseCase)
 	admin := r.Group("/admin")
-	admin.Post("/login", q.AdminLogin)
-	admin.Use(bearerVerifier).
-		Get("/profile", q.AdminGetProfile).
-		Put("/password/change", q.AdminChangePassword)
+	admin.Post("/login", q.AdminLogin).
+		Post("/password/forgot", q.AdminForgotPassword).
+		Put("/password/reset", q.AdminResetPassword).
+		Post("/token/refresh", q.AdminRefreshToken)
+	// registered ahead of admin.Use so admins with a pending mandatory enrolment can still reach them
+	totpEnrolment := middlewareRBAC.NewTOTPEnrolmentRBAC(roleModel.RoleSuperAdmin, roleModel.RoleAdmin)
+	admin.Post("/totp", bearerVerifier, totpEnrolment, q.EnrolTOTP).
+		Put("/totp/confirm", bearerVerifier, totpEnrolment, q.ConfirmTOTP).
+		Post("/logout", bearerVerifier, totpEnrolment, q.Logout)
+	admin.Use(bearerVerifier, middlewareRBAC.NewRBAC(roleModel.RoleSuperAdmin, roleModel.RoleAdmin)).
+		Get("/profile", q.GetProfile).
+		Put("/password/change", q.ChangePassword).
+		Delete("/totp", q.DisableTOTP).
+		Get("/totp/policy", q.FindTOTPPolicy).
+		Post("/email/change", q.RequestEmailChange).
+		Put("/email/change/confirm", q.ConfirmEmailChange).
+		Get("/sessions", q.FindSessions).
+		Delete("/sessions/others", q.RevokeOtherSessions).
+		Delete("/sessions/:session_id", q.RevokeSession).
+		Put("/totp/policy", middlewareRBAC.NewRBAC(roleModel.RoleSuperAdmin), q.SaveTOTPPolicy)
+	// impersonation tokens are only ever issued for buyers and sellers
+	noImpersonation := middlewareRBAC.NewNoImpersonation()
 	buyer := r.Group("/buyer")
 	buyer.Post("/register", q.BuyerRegister).
-		Put("/:token/activate", q.BuyerActivate).
-		Post("/login", q.BuyerLogin)
-	buyer.Use(bearerVerifier).
-		Get("/profile", q.BuyerGetProfile).
-		Put("/password/change", q.BuyerChangePassword)
+		Put("/activate", q.BuyerVerifyActivation).
+		Post("/activate/resend", q.BuyerResendActivation).
+		Post("/login", q.BuyerLogin).
+		Post("/login/otp", q.BuyerRequestLoginOTP).
+		Post("/login/otp/verify", q.BuyerVerifyLoginOTP).
+		Post("/password/forgot", q.BuyerForgotPassword).
+		Put("/password/reset", q.BuyerResetPassword).
+		Post("/token/refresh", q.BuyerRefreshToken)
+	if config.IsActivationTokenEnabled() {
+		buyer.Put("/:token/activate", q.BuyerActivate)
+	}
+	buyer.Use(bearerVerifier, middlewareRBAC.NewRBAC(roleModel.RoleBuyer)).
+		Get("/profile", q.GetProfile).
+		Put("/profile", q.UpdateProfile).
+		Put("/password/change", noImpersonation, q.ChangePassword).
+		Post("/logout", q.Logout).
+		Post("/mobile-phone/change", noImpersonation, q.RequestMobilePhoneChange).
+		Put("/mobile-phone/change/confirm", noImpersonation, q.ConfirmMobilePhoneChange).
+		Post("/email/change", noImpersonation, q.RequestEmailChange).
+		Put("/email/change/confirm", noImpersonation, q.ConfirmEmailChange).
+		Get("/account/export", q.ExportAccount).
+		Post("/account/deletion", noImpersonation, q.RequestAccountDeletion).
+		Delete("/account/deletion", noImpersonation, q.CancelAccountDeletion).
+		Get("/sessions", q.FindSessions).
+		Delete("/sessions/others", q.RevokeOtherSessions).
+		Delete("/sessions/:session_id", q.RevokeSession)
 	seller := r.Group("/seller")
 	seller.Post("/register", q.SellerRegister).
-		Put("/:token/activate", q.SellerActivate).
-		Post("/login", q.SellerLogin)
-	seller.Use(bearerVerifier).
-		Get("/profile", q.SellerGetProfile).
-		Put("/password/change", q.SellerChangePassword)
+		Put("/activate", q.SellerVerifyActivation).
+		Post("/activate/resend", q.SellerResendActivation).
+		Post("/login", q.SellerLogin).
+		Post("/password/forgot", q.SellerForgotPassword).
+		Put("/password/reset", q.SellerResetPassword).
+		Post("/token/refresh", q.SellerRefreshToken)
+	if config.IsActivationTokenEnabled() {
+		seller.Put("/:token/activate", q.SellerActivate)
+	}
+	seller.Use(bearerVerifier, middlewareRBAC.NewRBAC(roleModel.RoleSeller)).
+		Get("/profile", q.GetProfile).
+		Put("/profile", q.UpdateProfile).
+		Put("/password/change", noImpersonation, q.ChangePassword).
+		Post("/logout", q.Logout).
+		Post("/mobile-phone/change", noImpersonation, q.RequestMobilePhoneChange).
+		Put("/mobile-phone/change/confirm", noImpersonation, q.ConfirmMobilePhoneChange).
+		Post("/email/change", noImpersonation, q.RequestEmailChange).
+		Put("/email/change/confirm", noImpersonation, q.ConfirmEmailChange).
+		Get("/account/export", q.ExportAccount).
+		Post("/account/deletion", noImpersonation, q.RequestAccountDeletion).
+		Delete("/account/deletion", noImpersonation, q.CancelAccountDeletion).
+		Get("/sessions", q.FindSessions).
+		Delete("/sessions/others", q.RevokeOtherSessions).
+		Delete("/sessions/:session_id", q.RevokeSession)
+}
+
+func (q *authHTTPHandler) MountWellKnown(r fiber.Router) {
+	r.Get("/jwks.json", q.FindJWKS)
 }
 
 func (q *authHTTPHandler) AdminLogin(c *fiber.Ctx) error {
@@ -71,69 +135,50 @@ func (q *authHTTPHandler) AdminLogin(c *fiber.Ctx) error {
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
-func (q *authHTTPHandler) AdminGetProfile(c *fiber.Ctx) error {
+func (q *authHTTPHandler) AdminForgotPassword(c *fiber.Ctx) error {
 	ctx := context.Background()
-	ctxt := "AuthPresenter-AdminGetProfile"
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID, ok := claims["id"].(float64)
-	if !ok || userID < 1 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
-	}
-	users, err := q.userUseCase.FindUsers(
-		ctx,
-		userModel.UserFilter{
-			RoleIDs: []int64{roleModel.RoleSuperAdmin, roleModel.RoleAdmin},
-			Status:  []int{userModel.StatusActive},
-			UserIDs: []int64{int64(userID)},
-		},
-	)
+	ctxt := "AuthPresenter-AdminForgotPassword"
+	request, statusCode, err := sanitizer.ForgotPassword(ctx, c)
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
-		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrForgotPassword")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	if len(users) == 0 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	if err = q.authUseCase.ForgotPassword(ctx, []int64{roleModel.RoleSuperAdmin, roleModel.RoleAdmin}, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrForgotPassword")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
 	}
-	response := users[0]
-	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
 }
 
-func (q *authHTTPHandler) AdminChangePassword(c *fiber.Ctx) error {
+func (q *authHTTPHandler) AdminResetPassword(c *fiber.Ctx) error {
 	ctx := context.Background()
-	ctxt := "AuthPresenter-AdminChangePassword"
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID, ok := claims["id"].(float64)
-	if !ok || userID < 1 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
-	}
-	users, err := q.userUseCase.FindUsers(
-		ctx,
-		userModel.UserFilter{
-			RoleIDs: []int64{roleModel.RoleSuperAdmin, roleModel.RoleAdmin},
-			Status:  []int{userModel.StatusActive},
-			UserIDs: []int64{int64(userID)},
-		},
-	)
+	ctxt := "AuthPresenter-AdminResetPassword"
+	request, statusCode, err := sanitizer.ResetPassword(ctx, c)
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
-		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	if len(users) == 0 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	if err = q.authUseCase.ResetPassword(ctx, []int64{roleModel.RoleSuperAdmin, roleModel.RoleAdmin}, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
 	}
-	currentUser := users[0]
-	request, statusCode, err := sanitizer.ChangePassword(ctx, c)
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) AdminRefreshToken(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-AdminRefreshToken"
+	request, statusCode, err := sanitizer.RefreshToken(ctx, c)
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRefreshToken")
 		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	if err = q.authUseCase.ChangePassword(ctx, currentUser.ID, currentUser.Password, request); err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
-		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	response, err := q.authUseCase.RefreshToken(ctx, []int64{roleModel.RoleSuperAdmin, roleModel.RoleAdmin}, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRefreshToken")
+		return helper.NewResponse(fiber.StatusUnauthorized, err.Error(), nil).WriteResponse(c)
 	}
-	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
 func (q *authHTTPHandler) BuyerRegister(c *fiber.Ctx) error {
@@ -157,7 +202,7 @@ func (q *authHTTPHandler) BuyerActivate(c *fiber.Ctx) error {
 	ctx := context.Background()
 	ctxt := "AuthPresenter-BuyerActivate"
 	activationToken := c.Params("token")
-	response, err := q.authUseCase.Activate(ctx, roleModel.RoleBuyer, activationToken)
+	response, err := q.authUseCase.Activate(ctx, roleModel.RoleBuyer, activationToken, sanitizer.SessionClient(c, ""))
 	if err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivate")
 		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
@@ -165,6 +210,38 @@ func (q *authHTTPHandler) BuyerActivate(c *fiber.Ctx) error {
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
+func (q *authHTTPHandler) BuyerVerifyActivation(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-BuyerVerifyActivation"
+	request, statusCode, err := sanitizer.Activate(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivate")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.VerifyActivation(ctx, roleModel.RoleBuyer, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyActivation")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) BuyerResendActivation(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-BuyerResendActivation"
+	request, statusCode, err := sanitizer.ResendActivation(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResendActivation")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.ResendActivation(ctx, roleModel.RoleBuyer, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResendActivation")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
 func (q *authHTTPHandler) BuyerLogin(c *fiber.Ctx) error {
 	ctx := context.Background()
 	ctxt := "AuthPresenter-BuyerLogin"
@@ -181,71 +258,84 @@ func (q *authHTTPHandler) BuyerLogin(c *fiber.Ctx) error {
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
-func (q *authHTTPHandler) BuyerGetProfile(c *fiber.Ctx) error {
+func (q *authHTTPHandler) BuyerRequestLoginOTP(c *fiber.Ctx) error {
 	ctx := context.Background()
-	ctxt := "AuthPresenter-BuyerGetProfile"
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID, ok := claims["id"].(float64)
-	if !ok || userID < 1 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	ctxt := "AuthPresenter-BuyerRequestLoginOTP"
+	request, statusCode, err := sanitizer.LoginOTP(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrLoginOTP")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	users, err := q.userUseCase.FindUsers(
-		ctx,
-		userModel.UserFilter{
-			RoleIDs: []int64{roleModel.RoleBuyer},
-			Status:  []int{userModel.StatusActive},
-			UserIDs: []int64{int64(userID)},
-		},
-	)
+	response, err := q.authUseCase.RequestLoginOTP(ctx, []int64{roleModel.RoleBuyer}, request)
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRequestLoginOTP")
 		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
 	}
-	if len(users) == 0 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
-	}
-	response := users[0]
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
-func (q *authHTTPHandler) BuyerChangePassword(c *fiber.Ctx) error {
+func (q *authHTTPHandler) BuyerVerifyLoginOTP(c *fiber.Ctx) error {
 	ctx := context.Background()
-	ctxt := "AuthPresenter-BuyerChangePassword"
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID, ok := claims["id"].(float64)
-	if !ok || userID < 1 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	ctxt := "AuthPresenter-BuyerVerifyLoginOTP"
+	request, statusCode, err := sanitizer.VerifyLoginOTP(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyLoginOTP")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	users, err := q.userUseCase.FindUsers(
-		ctx,
-		userModel.UserFilter{
-			RoleIDs: []int64{roleModel.RoleBuyer},
-			Status:  []int{userModel.StatusActive},
-			UserIDs: []int64{int64(userID)},
-		},
-	)
+	response, err := q.authUseCase.VerifyLoginOTP(ctx, []int64{roleModel.RoleBuyer}, request)
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyLoginOTP")
 		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
 	}
-	if len(users) == 0 {
-		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) BuyerForgotPassword(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-BuyerForgotPassword"
+	request, statusCode, err := sanitizer.ForgotPassword(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrForgotPassword")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	currentUser := users[0]
-	request, statusCode, err := sanitizer.ChangePassword(ctx, c)
+	if err = q.authUseCase.ForgotPassword(ctx, []int64{roleModel.RoleBuyer}, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrForgotPassword")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) BuyerResetPassword(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-BuyerResetPassword"
+	request, statusCode, err := sanitizer.ResetPassword(ctx, c)
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
 		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	if err = q.authUseCase.ChangePassword(ctx, currentUser.ID, currentUser.Password, request); err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
+	if err = q.authUseCase.ResetPassword(ctx, []int64{roleModel.RoleBuyer}, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
 		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
 	}
 	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
 }
 
+func (q *authHTTPHandler) BuyerRefreshToken(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-BuyerRefreshToken"
+	request, statusCode, err := sanitizer.RefreshToken(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRefreshToken")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.RefreshToken(ctx, []int64{roleModel.RoleBuyer}, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRefreshToken")
+		return helper.NewResponse(fiber.StatusUnauthorized, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
 func (q *authHTTPHandler) SellerRegister(c *fiber.Ctx) error {
 	ctx := context.Background()
 	ctxt := "AuthPresenter-SellerRegister"
@@ -267,7 +357,7 @@ func (q *authHTTPHandler) SellerActivate(c *fiber.Ctx) error {
 	ctx := context.Background()
 	ctxt := "AuthPresenter-SellerActivate"
 	activationToken := c.Params("token")
-	response, err := q.authUseCase.Activate(ctx, roleModel.RoleSeller, activationToken)
+	response, err := q.authUseCase.Activate(ctx, roleModel.RoleSeller, activationToken, sanitizer.SessionClient(c, ""))
 	if err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivate")
 		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
@@ -275,6 +365,38 @@ func (q *authHTTPHandler) SellerActivate(c *fiber.Ctx) error {
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
+func (q *authHTTPHandler) SellerVerifyActivation(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-SellerVerifyActivation"
+	request, statusCode, err := sanitizer.Activate(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivate")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.VerifyActivation(ctx, roleModel.RoleSeller, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyActivation")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) SellerResendActivation(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-SellerResendActivation"
+	request, statusCode, err := sanitizer.ResendActivation(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResendActivation")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.ResendActivation(ctx, roleModel.RoleSeller, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResendActivation")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
 func (q *authHTTPHandler) SellerLogin(c *fiber.Ctx) error {
 	ctx := context.Background()
 	ctxt := "AuthPresenter-SellerLogin"
@@ -291,21 +413,64 @@ func (q *authHTTPHandler) SellerLogin(c *fiber.Ctx) error {
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
-func (q *authHTTPHandler) SellerGetProfile(c *fiber.Ctx) error {
+func (q *authHTTPHandler) SellerForgotPassword(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-SellerForgotPassword"
+	request, statusCode, err := sanitizer.ForgotPassword(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrForgotPassword")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	if err = q.authUseCase.ForgotPassword(ctx, []int64{roleModel.RoleSeller}, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrForgotPassword")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) SellerResetPassword(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-SellerResetPassword"
+	request, statusCode, err := sanitizer.ResetPassword(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	if err = q.authUseCase.ResetPassword(ctx, []int64{roleModel.RoleSeller}, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) SellerRefreshToken(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-SellerRefreshToken"
+	request, statusCode, err := sanitizer.RefreshToken(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRefreshToken")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.RefreshToken(ctx, []int64{roleModel.RoleSeller}, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRefreshToken")
+		return helper.NewResponse(fiber.StatusUnauthorized, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) GetProfile(c *fiber.Ctx) error {
 	ctx := context.Background()
-	ctxt := "AuthPresenter-SellerGetProfile"
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID, ok := claims["id"].(float64)
-	if !ok || userID < 1 {
+	ctxt := "AuthPresenter-GetProfile"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
 		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
 	}
 	users, err := q.userUseCase.FindUsers(
 		ctx,
 		userModel.UserFilter{
-			RoleIDs: []int64{roleModel.RoleSeller},
 			Status:  []int{userModel.StatusActive},
-			UserIDs: []int64{int64(userID)},
+			UserIDs: []int64{currentUser.ID},
 		},
 	)
 	if err != nil {
@@ -319,21 +484,18 @@ func (q *authHTTPHandler) SellerGetProfile(c *fiber.Ctx) error {
 	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
 }
 
-func (q *authHTTPHandler) SellerChangePassword(c *fiber.Ctx) error {
+func (q *authHTTPHandler) ChangePassword(c *fiber.Ctx) error {
 	ctx := context.Background()
-	ctxt := "AuthPresenter-SellerChangePassword"
-	user := c.Locals("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	userID, ok := claims["id"].(float64)
-	if !ok || userID < 1 {
+	ctxt := "AuthPresenter-ChangePassword"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
 		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
 	}
 	users, err := q.userUseCase.FindUsers(
 		ctx,
 		userModel.UserFilter{
-			RoleIDs: []int64{roleModel.RoleSeller},
 			Status:  []int{userModel.StatusActive},
-			UserIDs: []int64{int64(userID)},
+			UserIDs: []int64{currentUser.ID},
 		},
 	)
 	if err != nil {
@@ -343,15 +505,315 @@ func (q *authHTTPHandler) SellerChangePassword(c *fiber.Ctx) error {
 	if len(users) == 0 {
 		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
 	}
-	currentUser := users[0]
+	user := users[0]
 	request, statusCode, err := sanitizer.ChangePassword(ctx, c)
 	if err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
 		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
 	}
-	if err = q.authUseCase.ChangePassword(ctx, currentUser.ID, currentUser.Password, request); err != nil {
+	if err = q.authUseCase.ChangePassword(ctx, user.ID, user.Password, request); err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
 		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
 	}
 	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
 }
+
+func (q *authHTTPHandler) Logout(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-Logout"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	if err := q.authUseCase.Logout(ctx, *currentUser); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrLogout")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) RequestMobilePhoneChange(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-RequestMobilePhoneChange"
+	request, statusCode, err := sanitizer.MobilePhoneChange(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrMobilePhoneChange")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.RequestMobilePhoneChange(ctx, *currentUser, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRequestMobilePhoneChange")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) ConfirmMobilePhoneChange(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-ConfirmMobilePhoneChange"
+	request, statusCode, err := sanitizer.ConfirmMobilePhoneChange(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConfirmMobilePhoneChange")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.ConfirmMobilePhoneChange(ctx, *currentUser, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConfirmMobilePhoneChange")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) RequestEmailChange(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-RequestEmailChange"
+	request, statusCode, err := sanitizer.EmailChange(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrEmailChange")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.RequestEmailChange(ctx, *currentUser, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRequestEmailChange")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-ConfirmEmailChange"
+	request, statusCode, err := sanitizer.ConfirmEmailChange(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConfirmEmailChange")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.ConfirmEmailChange(ctx, *currentUser, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConfirmEmailChange")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) UpdateProfile(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-UpdateProfile"
+	request, statusCode, err := sanitizer.UpdateProfile(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUpdateProfile")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.UpdateProfile(ctx, *currentUser, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUpdateProfile")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) ExportAccount(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-ExportAccount"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.ExportAccount(ctx, *currentUser)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrExportAccount")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="laukpauk-account.json"`)
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) RequestAccountDeletion(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-RequestAccountDeletion"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.RequestAccountDeletion(ctx, *currentUser)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRequestAccountDeletion")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) CancelAccountDeletion(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-CancelAccountDeletion"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	if err := q.authUseCase.CancelAccountDeletion(ctx, *currentUser); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCancelAccountDeletion")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) FindSessions(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-FindSessions"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.FindSessions(ctx, *currentUser)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSessions")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) RevokeSession(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-RevokeSession"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	sessionID, _ := strconv.ParseInt(c.Params("session_id"), 10, 64)
+	if err := q.authUseCase.RevokeSession(ctx, *currentUser, sessionID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSession")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-RevokeOtherSessions"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	if err := q.authUseCase.RevokeOtherSessions(ctx, *currentUser); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeOtherSessions")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) FindJWKS(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-FindJWKS"
+	response, err := q.authUseCase.FindJWKS(ctx)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindJWKS")
+		return helper.NewResponse(fiber.StatusInternalServerError, err.Error(), nil).WriteResponse(c)
+	}
+	// served as a bare JWK set instead of the usual envelope so standard JWKS clients can consume it
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(response)
+}
+
+func (q *authHTTPHandler) EnrolTOTP(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-EnrolTOTP"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.EnrolTOTP(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrEnrolTOTP")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) ConfirmTOTP(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-ConfirmTOTP"
+	request, statusCode, err := sanitizer.TOTPCode(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrTOTPCode")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.authUseCase.ConfirmTOTP(ctx, currentUser.ID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConfirmTOTP")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) DisableTOTP(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-DisableTOTP"
+	request, statusCode, err := sanitizer.TOTPCode(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrTOTPCode")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	if err = q.authUseCase.DisableTOTP(ctx, currentUser.ID, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrDisableTOTP")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) FindTOTPPolicy(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-FindTOTPPolicy"
+	response, err := q.authUseCase.FindTOTPPolicy(ctx)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindTOTPPolicy")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *authHTTPHandler) SaveTOTPPolicy(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "AuthPresenter-SaveTOTPPolicy"
+	request, statusCode, err := sanitizer.TOTPPolicy(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrTOTPPolicy")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	if err = q.authUseCase.SaveTOTPPolicy(ctx, request, currentUser.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSaveTOTPPolicy")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
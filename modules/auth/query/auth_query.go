@@ -0,0 +1,1041 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/auth/model"
+	"go.uber.org/zap"
+)
+
+type (
+	authQuery struct {
+		dbRead, dbWrite *pgxpool.Pool
+	}
+)
+
+func NewAuthQuery(
+	dbRead,
+	dbWrite *pgxpool.Pool,
+) AuthQuery {
+	return &authQuery{
+		dbRead:  dbRead,
+		dbWrite: dbWrite,
+	}
+}
+
+func (q *authQuery) CreateSession(ctx context.Context, userID int64, client model.SessionClient, refreshTokenHash string, expiresAt time.Time) (*model.Session, error) {
+	ctxt := "AuthQuery-CreateSession"
+	sessionID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	refreshTokenID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	now := time.Now().UTC()
+	response := model.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		Device:     getNullableString(client.Device),
+		IpAddress:  client.IpAddress,
+		UserAgent:  getNullableString(client.UserAgent),
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt.UTC(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return nil, err
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`INSERT INTO sessions (
+			id
+			, user_id
+			, device
+			, ip_address
+			, user_agent
+			, last_seen_at
+			, expires_at
+			, created_at
+			, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $6, $6)`,
+		response.ID,
+		response.UserID,
+		response.Device,
+		getNullableIP(response.IpAddress),
+		response.UserAgent,
+		now,
+		response.ExpiresAt,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return nil, err
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`INSERT INTO refresh_tokens (
+			id
+			, session_id
+			, token_hash
+			, expires_at
+			, created_at
+		) VALUES ($1, $2, $3, $4, $5)`,
+		refreshTokenID,
+		response.ID,
+		refreshTokenHash,
+		response.ExpiresAt,
+		now,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+		return nil, err
+	}
+	return &response, nil
+}
+
+// CreateImpersonationSession opens a session without a refresh token on behalf of impersonatedBy,
+// it only exists so that revoking the user's sessions ends the impersonation as well.
+func (q *authQuery) CreateImpersonationSession(ctx context.Context, userID, impersonatedBy int64, client model.SessionClient, expiresAt time.Time) (*model.Session, error) {
+	ctxt := "AuthQuery-CreateImpersonationSession"
+	sessionID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	now := time.Now().UTC()
+	response := model.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		IpAddress:  client.IpAddress,
+		UserAgent:  getNullableString(client.UserAgent),
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt.UTC(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO sessions (
+			id
+			, user_id
+			, impersonated_by
+			, ip_address
+			, user_agent
+			, last_seen_at
+			, expires_at
+			, created_at
+			, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $6, $6)`,
+		response.ID,
+		response.UserID,
+		impersonatedBy,
+		getNullableIP(response.IpAddress),
+		response.UserAgent,
+		now,
+		response.ExpiresAt,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *authQuery) FindRefreshToken(ctx context.Context, refreshTokenHash string) (*model.RefreshToken, error) {
+	ctxt := "AuthQuery-FindRefreshToken"
+	var response model.RefreshToken
+	// read from the primary so a token rotated a moment ago can't be replayed against a lagging replica
+	err := q.dbWrite.QueryRow(
+		ctx,
+		`SELECT
+			rt.id
+			, rt.session_id
+			, rt.expires_at
+			, rt.used_at
+			, s.id
+			, s.user_id
+			, s.expires_at
+			, s.revoked_at
+			, s.created_at
+			, s.updated_at
+		FROM refresh_tokens rt
+		JOIN sessions s ON rt.session_id = s.id
+		WHERE rt.token_hash = $1`,
+		refreshTokenHash,
+	).Scan(
+		&response.ID,
+		&response.SessionID,
+		&response.ExpiresAt,
+		&response.UsedAt,
+		&response.Session.ID,
+		&response.Session.UserID,
+		&response.Session.ExpiresAt,
+		&response.Session.RevokedAt,
+		&response.Session.CreatedAt,
+		&response.Session.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		return nil, err
+	}
+	response.UserID = response.Session.UserID
+	return &response, nil
+}
+
+func (q *authQuery) RotateRefreshToken(ctx context.Context, refreshToken model.RefreshToken, client model.SessionClient, newRefreshTokenHash string, expiresAt time.Time) (err error) {
+	ctxt := "AuthQuery-RotateRefreshToken"
+	refreshTokenID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return
+	}
+	now := time.Now().UTC()
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return
+	}
+	commandTag, err := tx.Exec(
+		ctx,
+		`UPDATE refresh_tokens SET
+			used_at = $1
+		WHERE id = $2
+		AND used_at IS NULL`,
+		now,
+		refreshToken.ID,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	// another request already rotated this refresh token
+	if commandTag.RowsAffected() == 0 {
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return model.ErrInvalidRefreshToken
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`INSERT INTO refresh_tokens (
+			id
+			, session_id
+			, token_hash
+			, expires_at
+			, created_at
+		) VALUES ($1, $2, $3, $4, $5)`,
+		refreshTokenID,
+		refreshToken.SessionID,
+		newRefreshTokenHash,
+		expiresAt.UTC(),
+		now,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`UPDATE sessions SET
+			expires_at = $1
+			, ip_address = COALESCE($2, ip_address)
+			, user_agent = COALESCE($3, user_agent)
+			, last_seen_at = $4
+			, updated_at = $4
+		WHERE id = $5`,
+		expiresAt.UTC(),
+		getNullableIP(client.IpAddress),
+		getNullableString(client.UserAgent),
+		now,
+		refreshToken.SessionID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+	}
+	return
+}
+
+func (q *authQuery) RevokeSessions(ctx context.Context, userID int64, sessionIDs ...int64) (err error) {
+	ctxt := "AuthQuery-RevokeSessions"
+	now := time.Now().UTC()
+	params := []interface{}{now, userID}
+	conditions := []string{
+		"user_id = $2",
+		"revoked_at IS NULL",
+	}
+	if n := len(sessionIDs); n > 0 {
+		placeholders := make([]string, n)
+		for i, sessionID := range sessionIDs {
+			params = append(params, sessionID)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		conditions = append(conditions, fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE sessions SET
+				revoked_at = $1
+				, updated_at = $1
+			WHERE %s`,
+			strings.Join(conditions, " AND "),
+		),
+		params...,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func (q *authQuery) FindSessions(ctx context.Context, userID int64) (response []model.Session, err error) {
+	ctxt := "AuthQuery-FindSessions"
+	rows, err := q.dbWrite.Query(
+		ctx,
+		`SELECT
+			id
+			, user_id
+			, device
+			, ip_address
+			, user_agent
+			, last_seen_at
+			, expires_at
+			, revoked_at
+			, created_at
+			, updated_at
+		FROM sessions
+		WHERE user_id = $1
+		AND impersonated_by IS NULL
+		AND revoked_at IS NULL
+		AND expires_at > $2
+		ORDER BY last_seen_at DESC`,
+		userID,
+		time.Now().UTC(),
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var session model.Session
+		if err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.Device,
+			&session.IpAddress,
+			&session.UserAgent,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+			&session.RevokedAt,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		response = append(response, session)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+func (q *authQuery) RevokeOtherSessions(ctx context.Context, userID, currentSessionID int64) (err error) {
+	ctxt := "AuthQuery-RevokeOtherSessions"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE sessions SET
+			revoked_at = $1
+			, updated_at = $1
+		WHERE user_id = $2
+		AND id <> $3
+		AND impersonated_by IS NULL
+		AND revoked_at IS NULL`,
+		time.Now().UTC(),
+		userID,
+		currentSessionID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func (q *authQuery) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) (err error) {
+	ctxt := "AuthQuery-RevokeToken"
+	now := time.Now().UTC()
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO revoked_tokens (
+			jti
+			, expires_at
+			, created_at
+		) VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`,
+		tokenID,
+		expiresAt.UTC(),
+		now,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	// expired tokens already fail the exp check, no need to keep them around
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`DELETE FROM revoked_tokens
+		WHERE expires_at < $1`,
+		now,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func (q *authQuery) IsTokenRevoked(ctx context.Context, tokenID string, sessionID int64) (response bool, err error) {
+	ctxt := "AuthQuery-IsTokenRevoked"
+	if err = q.dbWrite.QueryRow(
+		ctx,
+		`SELECT EXISTS(
+			SELECT 1
+			FROM revoked_tokens
+			WHERE jti = $1
+		) OR EXISTS(
+			SELECT 1
+			FROM sessions
+			WHERE id = $2
+			AND revoked_at IS NOT NULL
+		)`,
+		tokenID,
+		sessionID,
+	).Scan(&response); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *authQuery) FindLoginFailures(ctx context.Context, keys ...string) (response []model.LoginFailure, err error) {
+	ctxt := "AuthQuery-FindLoginFailures"
+	n := len(keys)
+	if n == 0 {
+		return
+	}
+	params := make([]interface{}, n)
+	placeholders := make([]string, n)
+	for i, key := range keys {
+		params[i] = key
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	rows, err := q.dbWrite.Query(
+		ctx,
+		fmt.Sprintf(
+			`SELECT
+				key
+				, failed_count
+				, last_failed_at
+				, locked_until
+			FROM login_failures
+			WHERE key IN (%s)`,
+			strings.Join(placeholders, ","),
+		),
+		params...,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var loginFailure model.LoginFailure
+		if err = rows.Scan(
+			&loginFailure.Key,
+			&loginFailure.FailedCount,
+			&loginFailure.LastFailedAt,
+			&loginFailure.LockedUntil,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		response = append(response, loginFailure)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+func (q *authQuery) RecordLoginFailure(ctx context.Context, key string, resetBefore time.Time) (*model.LoginFailure, error) {
+	ctxt := "AuthQuery-RecordLoginFailure"
+	now := time.Now().UTC()
+	response := model.LoginFailure{
+		Key: key,
+	}
+	// counters left alone for longer than the lockout window start over
+	if err := q.dbWrite.QueryRow(
+		ctx,
+		`INSERT INTO login_failures (
+			key
+			, failed_count
+			, last_failed_at
+		) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failed_count = CASE
+				WHEN login_failures.last_failed_at < $3 THEN 1
+				ELSE login_failures.failed_count + 1
+			END
+			, last_failed_at = EXCLUDED.last_failed_at
+			, locked_until = CASE
+				WHEN login_failures.locked_until < EXCLUDED.last_failed_at THEN NULL
+				ELSE login_failures.locked_until
+			END
+		RETURNING failed_count
+			, last_failed_at
+			, locked_until`,
+		key,
+		now,
+		resetBefore.UTC(),
+	).Scan(
+		&response.FailedCount,
+		&response.LastFailedAt,
+		&response.LockedUntil,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *authQuery) LockLogin(ctx context.Context, key string, lockedUntil time.Time) (err error) {
+	ctxt := "AuthQuery-LockLogin"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE login_failures SET
+			locked_until = $1
+		WHERE key = $2`,
+		lockedUntil.UTC(),
+		key,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func (q *authQuery) ClearLoginFailures(ctx context.Context, keys ...string) (err error) {
+	ctxt := "AuthQuery-ClearLoginFailures"
+	n := len(keys)
+	if n == 0 {
+		return
+	}
+	params := make([]interface{}, n)
+	placeholders := make([]string, n)
+	for i, key := range keys {
+		params[i] = key
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		fmt.Sprintf(
+			`DELETE FROM login_failures
+			WHERE key IN (%s)`,
+			strings.Join(placeholders, ","),
+		),
+		params...,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+// CreateOTP invalidates any outstanding code of the same purpose so only the latest one sent can be used
+func (q *authQuery) CreateOTP(ctx context.Context, userID int64, purpose, recipient, codeHash string, expiresAt time.Time) (*model.OTP, error) {
+	ctxt := "AuthQuery-CreateOTP"
+	otpID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	now := time.Now().UTC()
+	response := model.OTP{
+		ID:        otpID,
+		UserID:    userID,
+		Purpose:   purpose,
+		Recipient: recipient,
+		CodeHash:  codeHash,
+		ExpiresAt: expiresAt.UTC(),
+		CreatedAt: now,
+	}
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return nil, err
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`UPDATE one_time_passwords SET
+			consumed_at = $1
+		WHERE user_id = $2
+		AND purpose = $3
+		AND consumed_at IS NULL`,
+		now,
+		userID,
+		purpose,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return nil, err
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`INSERT INTO one_time_passwords (
+			id
+			, user_id
+			, purpose
+			, recipient
+			, code_hash
+			, expires_at
+			, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		response.ID,
+		response.UserID,
+		response.Purpose,
+		response.Recipient,
+		response.CodeHash,
+		response.ExpiresAt,
+		response.CreatedAt,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *authQuery) FindOTPSentTimes(ctx context.Context, recipient string, since time.Time) (response []time.Time, err error) {
+	ctxt := "AuthQuery-FindOTPSentTimes"
+	rows, err := q.dbWrite.Query(
+		ctx,
+		`SELECT created_at
+		FROM one_time_passwords
+		WHERE recipient = $1
+		AND created_at > $2
+		ORDER BY created_at`,
+		recipient,
+		since.UTC(),
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var createdAt time.Time
+		if err = rows.Scan(&createdAt); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		response = append(response, createdAt)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+func (q *authQuery) FindLatestOTP(ctx context.Context, userID int64, purpose string) (*model.OTP, error) {
+	ctxt := "AuthQuery-FindLatestOTP"
+	var response model.OTP
+	err := q.dbWrite.QueryRow(
+		ctx,
+		`SELECT
+			id
+			, user_id
+			, purpose
+			, recipient
+			, code_hash
+			, attempts
+			, expires_at
+			, consumed_at
+			, created_at
+		FROM one_time_passwords
+		WHERE user_id = $1
+		AND purpose = $2
+		ORDER BY created_at DESC
+		LIMIT 1`,
+		userID,
+		purpose,
+	).Scan(
+		&response.ID,
+		&response.UserID,
+		&response.Purpose,
+		&response.Recipient,
+		&response.CodeHash,
+		&response.Attempts,
+		&response.ExpiresAt,
+		&response.ConsumedAt,
+		&response.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *authQuery) IncrementOTPAttempts(ctx context.Context, otpID int64) (err error) {
+	ctxt := "AuthQuery-IncrementOTPAttempts"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE one_time_passwords SET
+			attempts = attempts + 1
+		WHERE id = $1`,
+		otpID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+// ConsumeOTP reports false when a concurrent request already used the code
+func (q *authQuery) ConsumeOTP(ctx context.Context, otpID int64) (response bool, err error) {
+	ctxt := "AuthQuery-ConsumeOTP"
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`UPDATE one_time_passwords SET
+			consumed_at = $1
+		WHERE id = $2
+		AND consumed_at IS NULL`,
+		time.Now().UTC(),
+		otpID,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected() > 0
+	return
+}
+
+func (q *authQuery) FindTOTP(ctx context.Context, userID int64) (*model.TOTP, error) {
+	ctxt := "AuthQuery-FindTOTP"
+	var response model.TOTP
+	err := q.dbWrite.QueryRow(
+		ctx,
+		`SELECT
+			user_id
+			, secret
+			, confirmed_at
+			, last_used_step
+		FROM user_totps
+		WHERE user_id = $1`,
+		userID,
+	).Scan(
+		&response.UserID,
+		&response.Secret,
+		&response.ConfirmedAt,
+		&response.LastUsedStep,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		return nil, err
+	}
+	return &response, nil
+}
+
+// SaveTOTPSecret replaces a pending enrolment but never a confirmed one
+func (q *authQuery) SaveTOTPSecret(ctx context.Context, userID int64, secret string) (err error) {
+	ctxt := "AuthQuery-SaveTOTPSecret"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO user_totps (
+			user_id
+			, secret
+			, created_at
+			, updated_at
+		) VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret
+			, last_used_step = NULL
+			, updated_at = EXCLUDED.updated_at
+		WHERE user_totps.confirmed_at IS NULL`,
+		userID,
+		secret,
+		time.Now().UTC(),
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func (q *authQuery) ConfirmTOTP(ctx context.Context, userID, step int64, recoveryCodeHashes []string) (err error) {
+	ctxt := "AuthQuery-ConfirmTOTP"
+	now := time.Now().UTC()
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return
+	}
+	commandTag, err := tx.Exec(
+		ctx,
+		`UPDATE user_totps SET
+			confirmed_at = $1
+			, last_used_step = $2
+			, updated_at = $1
+		WHERE user_id = $3
+		AND confirmed_at IS NULL`,
+		now,
+		step,
+		userID,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	if commandTag.RowsAffected() == 0 {
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return model.ErrTOTPEnrolled
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`DELETE FROM totp_recovery_codes WHERE user_id = $1`,
+		userID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	for _, recoveryCodeHash := range recoveryCodeHashes {
+		recoveryCodeID, errID := helper.GenerateSnowflakeUniqueID()
+		if errID != nil {
+			err = errID
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+			if errRollback := tx.Rollback(ctx); errRollback != nil {
+				helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+			}
+			return
+		}
+		if _, err = tx.Exec(
+			ctx,
+			`INSERT INTO totp_recovery_codes (
+				id
+				, user_id
+				, code_hash
+				, created_at
+			) VALUES ($1, $2, $3, $4)`,
+			recoveryCodeID,
+			userID,
+			recoveryCodeHash,
+			now,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+			if errRollback := tx.Rollback(ctx); errRollback != nil {
+				helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+			}
+			return
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+	}
+	return
+}
+
+// UseTOTPStep reports false when a code of the same or a later time step was already accepted
+func (q *authQuery) UseTOTPStep(ctx context.Context, userID, step int64) (response bool, err error) {
+	ctxt := "AuthQuery-UseTOTPStep"
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`UPDATE user_totps SET
+			last_used_step = $1
+			, updated_at = $2
+		WHERE user_id = $3
+		AND confirmed_at IS NOT NULL
+		AND (last_used_step IS NULL OR last_used_step < $1)`,
+		step,
+		time.Now().UTC(),
+		userID,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected() > 0
+	return
+}
+
+func (q *authQuery) UseRecoveryCode(ctx context.Context, userID int64, recoveryCodeHash string) (response bool, err error) {
+	ctxt := "AuthQuery-UseRecoveryCode"
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`UPDATE totp_recovery_codes SET
+			used_at = $1
+		WHERE user_id = $2
+		AND code_hash = $3
+		AND used_at IS NULL`,
+		time.Now().UTC(),
+		userID,
+		recoveryCodeHash,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected() > 0
+	return
+}
+
+func (q *authQuery) DeleteTOTP(ctx context.Context, userID int64) (err error) {
+	ctxt := "AuthQuery-DeleteTOTP"
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`DELETE FROM totp_recovery_codes WHERE user_id = $1`,
+		userID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`DELETE FROM user_totps WHERE user_id = $1`,
+		userID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+	}
+	return
+}
+
+func (q *authQuery) FindSetting(ctx context.Context, key string) (*string, error) {
+	ctxt := "AuthQuery-FindSetting"
+	var response string
+	err := q.dbRead.QueryRow(
+		ctx,
+		`SELECT value
+		FROM settings
+		WHERE key = $1`,
+		key,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *authQuery) SaveSetting(ctx context.Context, key, value string, updatedBy int64) (err error) {
+	ctxt := "AuthQuery-SaveSetting"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO settings (
+			key
+			, value
+			, updated_by
+			, updated_at
+		) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE SET
+			value = EXCLUDED.value
+			, updated_by = EXCLUDED.updated_by
+			, updated_at = EXCLUDED.updated_at`,
+		key,
+		value,
+		updatedBy,
+		time.Now().UTC(),
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func getNullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func getNullableIP(ipAddress net.IP) interface{} {
+	if ipAddress == nil {
+		return nil
+	}
+	return ipAddress
+}
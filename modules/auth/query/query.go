@@ -0,0 +1,39 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/modules/auth/model"
+)
+
+type (
+	AuthQuery interface {
+		CreateSession(ctx context.Context, userID int64, client model.SessionClient, refreshTokenHash string, expiresAt time.Time) (response *model.Session, err error)
+		CreateImpersonationSession(ctx context.Context, userID, impersonatedBy int64, client model.SessionClient, expiresAt time.Time) (response *model.Session, err error)
+		FindRefreshToken(ctx context.Context, refreshTokenHash string) (response *model.RefreshToken, err error)
+		RotateRefreshToken(ctx context.Context, refreshToken model.RefreshToken, client model.SessionClient, newRefreshTokenHash string, expiresAt time.Time) (err error)
+		FindSessions(ctx context.Context, userID int64) (response []model.Session, err error)
+		RevokeSessions(ctx context.Context, userID int64, sessionIDs ...int64) (err error)
+		RevokeOtherSessions(ctx context.Context, userID, currentSessionID int64) (err error)
+		RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) (err error)
+		IsTokenRevoked(ctx context.Context, tokenID string, sessionID int64) (response bool, err error)
+		FindLoginFailures(ctx context.Context, keys ...string) (response []model.LoginFailure, err error)
+		RecordLoginFailure(ctx context.Context, key string, resetBefore time.Time) (response *model.LoginFailure, err error)
+		LockLogin(ctx context.Context, key string, lockedUntil time.Time) (err error)
+		ClearLoginFailures(ctx context.Context, keys ...string) (err error)
+		CreateOTP(ctx context.Context, userID int64, purpose, recipient, codeHash string, expiresAt time.Time) (response *model.OTP, err error)
+		FindOTPSentTimes(ctx context.Context, recipient string, since time.Time) (response []time.Time, err error)
+		FindLatestOTP(ctx context.Context, userID int64, purpose string) (response *model.OTP, err error)
+		IncrementOTPAttempts(ctx context.Context, otpID int64) (err error)
+		ConsumeOTP(ctx context.Context, otpID int64) (response bool, err error)
+		FindTOTP(ctx context.Context, userID int64) (response *model.TOTP, err error)
+		SaveTOTPSecret(ctx context.Context, userID int64, secret string) (err error)
+		ConfirmTOTP(ctx context.Context, userID, step int64, recoveryCodeHashes []string) (err error)
+		UseTOTPStep(ctx context.Context, userID, step int64) (response bool, err error)
+		UseRecoveryCode(ctx context.Context, userID int64, recoveryCodeHash string) (response bool, err error)
+		DeleteTOTP(ctx context.Context, userID int64) (err error)
+		FindSetting(ctx context.Context, key string) (response *string, err error)
+		SaveSetting(ctx context.Context, key, value string, updatedBy int64) (err error)
+	}
+)
@@ -0,0 +1,67 @@
+package sanitizer
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/roysitumorang/laukpauk/config"
+)
+
+const (
+	// bcrypt silently ignores anything past 72 bytes
+	passwordMaxBytes = 72
+)
+
+var (
+	//go:embed common_passwords.txt
+	commonPasswordsFile string
+	commonPasswords     = map[string]struct{}{}
+)
+
+func init() {
+	for _, line := range strings.Split(commonPasswordsFile, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			commonPasswords[strings.ToLower(line)] = struct{}{}
+		}
+	}
+}
+
+// validatePassword enforces the configured password policy on a decoded password.
+func validatePassword(password string) error {
+	if minLength := config.GetPasswordMinLength(); utf8.RuneCountInString(password) < minLength {
+		return fmt.Errorf("password must be at least %d characters", minLength)
+	}
+	if len(password) > passwordMaxBytes {
+		return fmt.Errorf("password must be at most %d bytes", passwordMaxBytes)
+	}
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	var classes int
+	for _, ok := range []bool{hasLower, hasUpper, hasDigit, hasSymbol} {
+		if ok {
+			classes++
+		}
+	}
+	if minClasses := config.GetPasswordMinClasses(); classes < minClasses {
+		return fmt.Errorf("password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", minClasses)
+	}
+	if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+		return errors.New("password is too common, choose a less guessable one")
+	}
+	return nil
+}
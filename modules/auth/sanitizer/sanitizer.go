@@ -4,15 +4,28 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nyaruka/phonenumbers"
 	"github.com/roysitumorang/laukpauk/helper"
 	"github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
 	"go.uber.org/zap"
 )
 
+const (
+	maxDeviceLength    = 100
+	maxUserAgentLength = 255
+	maxNameLength      = 100
+	maxCompanyLength   = 100
+	dateOfBirthLayout  = "2006-01-02"
+)
+
 func Register(ctx context.Context, c *fiber.Ctx) (request model.RegisterRequest, statusCode int, err error) {
 	ctxt := "AuthSanitizer-Register"
 	statusCode = fiber.StatusBadRequest
@@ -43,6 +56,9 @@ func Register(ctx context.Context, c *fiber.Ctx) (request model.RegisterRequest,
 		return
 	}
 	request.Password = helper.ByteSlice2String(password)
+	if err = validatePassword(request.Password); err != nil {
+		return
+	}
 	if request.VillageID == 0 {
 		err = errors.New("village_id is required")
 		return
@@ -56,6 +72,68 @@ func Register(ctx context.Context, c *fiber.Ctx) (request model.RegisterRequest,
 	return
 }
 
+func UpdateProfile(ctx context.Context, c *fiber.Ctx) (request model.UpdateProfileRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-UpdateProfile"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.Name = strings.TrimSpace(request.Name); request.Name == "" {
+		err = errors.New("name is required")
+		return
+	}
+	if len([]rune(request.Name)) > maxNameLength {
+		err = fmt.Errorf("name must not exceed %d characters", maxNameLength)
+		return
+	}
+	if request.VillageID == 0 {
+		err = errors.New("village_id is required")
+		return
+	}
+	if request.Address = strings.TrimSpace(request.Address); request.Address == "" {
+		err = errors.New("address is required")
+		return
+	}
+	if request.Gender != nil {
+		if gender := strings.TrimSpace(*request.Gender); gender == "" {
+			request.Gender = nil
+		} else if !slices.Contains(userModel.Genders, gender) {
+			err = fmt.Errorf("invalid gender, valid genders are %s", strings.Join(userModel.Genders, ", "))
+			return
+		} else {
+			request.Gender = &gender
+		}
+	}
+	if request.DateOfBirth = strings.TrimSpace(request.DateOfBirth); request.DateOfBirth != "" {
+		dateOfBirth, errParse := time.Parse(dateOfBirthLayout, request.DateOfBirth)
+		if errParse != nil {
+			err = fmt.Errorf("date_of_birth must be formatted as %s", dateOfBirthLayout)
+			return
+		}
+		if !dateOfBirth.Before(time.Now()) {
+			err = errors.New("date_of_birth must be in the past")
+			return
+		}
+		request.BirthDate = &dateOfBirth
+	}
+	if request.Company != nil {
+		if company := strings.TrimSpace(*request.Company); company == "" {
+			request.Company = nil
+		} else if len([]rune(company)) > maxCompanyLength {
+			err = fmt.Errorf("company must not exceed %d characters", maxCompanyLength)
+			return
+		} else {
+			request.Company = &company
+		}
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
 func Login(ctx context.Context, c *fiber.Ctx) (request model.LoginRequest, statusCode int, err error) {
 	ctxt := "AuthSanitizer-Login"
 	statusCode = fiber.StatusBadRequest
@@ -70,6 +148,12 @@ func Login(ctx context.Context, c *fiber.Ctx) (request model.LoginRequest, statu
 		err = errors.New("mobile phone is required")
 		return
 	}
+	// normalise identifiers so lockout counters can't be dodged by rewriting the same number or email
+	if strings.Contains(request.MobilePhone, "@") {
+		request.MobilePhone = strings.ToLower(request.MobilePhone)
+	} else if phoneNumber, errParse := phonenumbers.Parse(request.MobilePhone, "ID"); errParse == nil {
+		request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	}
 	if request.Password = strings.TrimSpace(request.Password); request.Password == "" {
 		err = errors.New("password is required")
 		return
@@ -80,6 +164,9 @@ func Login(ctx context.Context, c *fiber.Ctx) (request model.LoginRequest, statu
 		return
 	}
 	request.Password = helper.ByteSlice2String(password)
+	request.TOTPCode = strings.TrimSpace(request.TOTPCode)
+	request.RecoveryCode = strings.TrimSpace(request.RecoveryCode)
+	request.SessionClient = SessionClient(c, request.Device)
 	statusCode = fiber.StatusOK
 	return
 }
@@ -114,6 +201,336 @@ func ChangePassword(ctx context.Context, c *fiber.Ctx) (request model.ChangePass
 	}
 	request.OldPassword = helper.ByteSlice2String(oldPassword)
 	request.NewPassword = helper.ByteSlice2String(newPassword)
+	if err = validatePassword(request.NewPassword); err != nil {
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+func ForgotPassword(ctx context.Context, c *fiber.Ctx) (request model.ForgotPasswordRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-ForgotPassword"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.MobilePhone = strings.TrimSpace(request.MobilePhone); request.MobilePhone == "" {
+		err = errors.New("mobile phone is required")
+		return
+	}
+	phoneNumber, err := phonenumbers.Parse(request.MobilePhone, "ID")
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParse")
+		return
+	}
+	request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	statusCode = fiber.StatusOK
+	return
+}
+
+func ResetPassword(ctx context.Context, c *fiber.Ctx) (request model.ResetPasswordRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-ResetPassword"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.PasswordResetToken = strings.TrimSpace(request.PasswordResetToken); request.PasswordResetToken == "" {
+		err = errors.New("password reset token is required")
+		return
+	}
+	if request.NewPassword = strings.TrimSpace(request.NewPassword); request.NewPassword == "" {
+		err = errors.New("new password is required")
+		return
+	}
+	newPassword, err := base64.StdEncoding.DecodeString(request.NewPassword)
+	if err != nil {
+		err = errors.New("invalid new password")
+		return
+	}
+	request.NewPassword = helper.ByteSlice2String(newPassword)
+	if err = validatePassword(request.NewPassword); err != nil {
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+func RefreshToken(ctx context.Context, c *fiber.Ctx) (request model.RefreshTokenRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-RefreshToken"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.RefreshToken = strings.TrimSpace(request.RefreshToken); request.RefreshToken == "" {
+		err = errors.New("refresh token is required")
+		return
+	}
+	request.SessionClient = SessionClient(c, request.Device)
+	statusCode = fiber.StatusOK
+	return
+}
+
+func Activate(ctx context.Context, c *fiber.Ctx) (request model.ActivateRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-Activate"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.MobilePhone = strings.TrimSpace(request.MobilePhone); request.MobilePhone == "" {
+		err = errors.New("mobile phone is required")
+		return
+	}
+	phoneNumber, err := phonenumbers.Parse(request.MobilePhone, "ID")
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParse")
+		return
+	}
+	request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	if request.OTP = strings.TrimSpace(request.OTP); request.OTP == "" {
+		err = errors.New("otp is required")
+		return
+	}
+	request.SessionClient = SessionClient(c, request.Device)
+	statusCode = fiber.StatusOK
+	return
+}
+
+func ResendActivation(ctx context.Context, c *fiber.Ctx) (request model.ResendActivationRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-ResendActivation"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.MobilePhone = strings.TrimSpace(request.MobilePhone); request.MobilePhone == "" {
+		err = errors.New("mobile phone is required")
+		return
+	}
+	phoneNumber, err := phonenumbers.Parse(request.MobilePhone, "ID")
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParse")
+		return
+	}
+	request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	statusCode = fiber.StatusOK
+	return
+}
+
+func TOTPCode(ctx context.Context, c *fiber.Ctx) (request model.TOTPCodeRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-TOTPCode"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	request.Code = strings.TrimSpace(request.Code)
+	request.RecoveryCode = strings.TrimSpace(request.RecoveryCode)
+	if request.Code == "" && request.RecoveryCode == "" {
+		err = errors.New("code is required")
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+func TOTPPolicy(ctx context.Context, c *fiber.Ctx) (request model.TOTPPolicy, statusCode int, err error) {
+	ctxt := "AuthSanitizer-TOTPPolicy"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+	}
+	// nothing else is validated, a malformed body must not reach the handler as a 200
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+// SessionClient describes the device a session is opened or refreshed from, the device name is
+// supplied by the app while the user agent and ip address come from the request itself.
+func SessionClient(c *fiber.Ctx, device string) model.SessionClient {
+	return model.SessionClient{
+		Device:    truncate(strings.TrimSpace(device), maxDeviceLength),
+		UserAgent: truncate(strings.TrimSpace(c.Get(fiber.HeaderUserAgent)), maxUserAgentLength),
+		IpAddress: helper.GetIPAdress(c.Request()),
+	}
+}
+
+func truncate(value string, maxLength int) string {
+	if runes := []rune(value); len(runes) > maxLength {
+		return string(runes[:maxLength])
+	}
+	return value
+}
+
+func MobilePhoneChange(ctx context.Context, c *fiber.Ctx) (request model.MobilePhoneChangeRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-MobilePhoneChange"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.MobilePhone = strings.TrimSpace(request.MobilePhone); request.MobilePhone == "" {
+		err = errors.New("mobile phone is required")
+		return
+	}
+	phoneNumber, err := phonenumbers.Parse(request.MobilePhone, "ID")
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParse")
+		return
+	}
+	// a code is about to be texted to this number, don't waste it on one that can't exist
+	if !phonenumbers.IsValidNumber(phoneNumber) {
+		err = errors.New("invalid mobile phone")
+		return
+	}
+	request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	statusCode = fiber.StatusOK
+	return
+}
+
+func ConfirmMobilePhoneChange(ctx context.Context, c *fiber.Ctx) (request model.ConfirmMobilePhoneChangeRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-ConfirmMobilePhoneChange"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.OTP = strings.TrimSpace(request.OTP); request.OTP == "" {
+		err = errors.New("otp is required")
+		return
+	}
+	request.IpAddress = helper.GetIPAdress(c.Request())
+	statusCode = fiber.StatusOK
+	return
+}
+
+func EmailChange(ctx context.Context, c *fiber.Ctx) (request model.EmailChangeRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-EmailChange"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.Email = strings.TrimSpace(request.Email); request.Email == "" {
+		err = errors.New("email is required")
+		return
+	}
+	address, err := mail.ParseAddress(request.Email)
+	// reject display names too, only a bare address can be used to log in
+	if err != nil || address.Address != request.Email {
+		err = errors.New("invalid email")
+		return
+	}
+	request.Email = strings.ToLower(address.Address)
+	statusCode = fiber.StatusOK
+	return
+}
+
+func ConfirmEmailChange(ctx context.Context, c *fiber.Ctx) (request model.ConfirmEmailChangeRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-ConfirmEmailChange"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.OTP = strings.TrimSpace(request.OTP); request.OTP == "" {
+		err = errors.New("otp is required")
+		return
+	}
+	request.IpAddress = helper.GetIPAdress(c.Request())
+	statusCode = fiber.StatusOK
+	return
+}
+
+func LoginOTP(ctx context.Context, c *fiber.Ctx) (request model.LoginOTPRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-LoginOTP"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.MobilePhone = strings.TrimSpace(request.MobilePhone); request.MobilePhone == "" {
+		err = errors.New("mobile phone is required")
+		return
+	}
+	phoneNumber, err := phonenumbers.Parse(request.MobilePhone, "ID")
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParse")
+		return
+	}
+	request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	request.IpAddress = helper.GetIPAdress(c.Request())
+	statusCode = fiber.StatusOK
+	return
+}
+
+func VerifyLoginOTP(ctx context.Context, c *fiber.Ctx) (request model.VerifyLoginOTPRequest, statusCode int, err error) {
+	ctxt := "AuthSanitizer-VerifyLoginOTP"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.MobilePhone = strings.TrimSpace(request.MobilePhone); request.MobilePhone == "" {
+		err = errors.New("mobile phone is required")
+		return
+	}
+	phoneNumber, err := phonenumbers.Parse(request.MobilePhone, "ID")
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParse")
+		return
+	}
+	request.MobilePhone = phonenumbers.Format(phoneNumber, phonenumbers.E164)
+	if request.OTP = strings.TrimSpace(request.OTP); request.OTP == "" {
+		err = errors.New("otp is required")
+		return
+	}
+	request.SessionClient = SessionClient(c, request.Device)
 	statusCode = fiber.StatusOK
 	return
 }
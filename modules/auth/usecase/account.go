@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+// UpdateProfile derives the subdistrict from the chosen village so both can never disagree.
+// While impersonating, updated_by records the admin rather than the user.
+func (q *authUseCaseImplementation) UpdateProfile(ctx context.Context, currentUser authModel.CurrentUser, request authModel.UpdateProfileRequest) (response userModel.User, err error) {
+	ctxt := "AuthUseCase-UpdateProfile"
+	village, err := q.regionQuery.FindVillageByID(ctx, request.VillageID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindVillageByID")
+		return
+	}
+	if village == nil || village.ID == 0 {
+		err = errors.New("village not found")
+		return
+	}
+	request.SubdistrictID = village.SubdistrictID
+	updatedBy := currentUser.ID
+	if currentUser.ImpersonatorID != 0 {
+		updatedBy = currentUser.ImpersonatorID
+	}
+	userID, err := q.userQuery.UpdateProfile(ctx, currentUser.ID, request, updatedBy)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUpdateProfile")
+		return
+	}
+	if userID == 0 {
+		err = errors.New("user not found")
+		return
+	}
+	if response, err = q.findActiveUser(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+		return
+	}
+	q.publishAccountEvent(
+		ctx,
+		map[string]interface{}{
+			"event":      "user.updated",
+			"user_id":    userID,
+			"updated_by": updatedBy,
+			"created_at": response.UpdatedAt,
+		},
+	)
+	return
+}
+
+func (q *authUseCaseImplementation) ExportAccount(ctx context.Context, currentUser authModel.CurrentUser) (response authModel.AccountExport, err error) {
+	ctxt := "AuthUseCase-ExportAccount"
+	if response.Profile, err = q.findActiveUser(ctx, currentUser.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+		return
+	}
+	if response.Sessions, err = q.FindSessions(ctx, currentUser); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSessions")
+		return
+	}
+	response.ExportedAt = time.Now().UTC()
+	return
+}
+
+// RequestAccountDeletion schedules the account for anonymisation, the user can still log in
+// and cancel until the grace period is over.
+func (q *authUseCaseImplementation) RequestAccountDeletion(ctx context.Context, currentUser authModel.CurrentUser) (response authModel.AccountDeletion, err error) {
+	ctxt := "AuthUseCase-RequestAccountDeletion"
+	now := time.Now().UTC()
+	userID, err := q.userQuery.RequestDeletion(ctx, currentUser.ID, now)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRequestDeletion")
+		return
+	}
+	if userID == 0 {
+		err = errors.New("account deletion already requested")
+		return
+	}
+	response.DeletionRequestedAt = now
+	response.AnonymisedAfter = now.Add(config.GetAccountDeletionGrace())
+	q.publishAccountEvent(
+		ctx,
+		map[string]interface{}{
+			"event":            "user.deletion_requested",
+			"user_id":          currentUser.ID,
+			"anonymised_after": response.AnonymisedAfter,
+			"created_at":       now,
+		},
+	)
+	return
+}
+
+func (q *authUseCaseImplementation) CancelAccountDeletion(ctx context.Context, currentUser authModel.CurrentUser) (err error) {
+	ctxt := "AuthUseCase-CancelAccountDeletion"
+	userID, err := q.userQuery.CancelDeletion(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCancelDeletion")
+		return
+	}
+	if userID == 0 {
+		err = errors.New("no pending account deletion")
+		return
+	}
+	q.publishAccountEvent(
+		ctx,
+		map[string]interface{}{
+			"event":      "user.deletion_cancelled",
+			"user_id":    currentUser.ID,
+			"created_at": time.Now().UTC(),
+		},
+	)
+	return
+}
+
+func (q *authUseCaseImplementation) publishAccountEvent(ctx context.Context, payload map[string]interface{}) {
+	ctxt := "AuthUseCase-publishAccountEvent"
+	if err := q.messagingProducer.Publish(config.TopicUser, payload); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrPublish")
+	}
+}
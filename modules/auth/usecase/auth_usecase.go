@@ -3,38 +3,71 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/config"
 	"github.com/roysitumorang/laukpauk/helper"
 	"github.com/roysitumorang/laukpauk/keys"
+	auditQuery "github.com/roysitumorang/laukpauk/modules/audit/query"
 	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	authQuery "github.com/roysitumorang/laukpauk/modules/auth/query"
 	regionQuery "github.com/roysitumorang/laukpauk/modules/region/query"
 	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
 	userQuery "github.com/roysitumorang/laukpauk/modules/user/query"
+	"github.com/roysitumorang/laukpauk/services/mailer"
+	"github.com/roysitumorang/laukpauk/services/messagingproducer"
+	"github.com/roysitumorang/laukpauk/services/notifier"
+	"github.com/roysitumorang/laukpauk/services/smssender"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type (
 	authUseCaseImplementation struct {
-		userQuery   userQuery.UserQuery
-		regionQuery regionQuery.RegionQuery
+		authQuery         authQuery.AuthQuery
+		auditQuery        auditQuery.AuditQuery
+		userQuery         userQuery.UserQuery
+		regionQuery       regionQuery.RegionQuery
+		notifier          notifier.NotifierService
+		smsSender         smssender.SMSSenderService
+		mailer            mailer.MailerService
+		messagingProducer messagingproducer.MessagingProducerService
 	}
 )
 
 func NewAuthUseCase(
+	authQuery authQuery.AuthQuery,
+	auditQuery auditQuery.AuditQuery,
 	userQuery userQuery.UserQuery,
 	regionQuery regionQuery.RegionQuery,
+	notifier notifier.NotifierService,
+	smsSender smssender.SMSSenderService,
+	mailer mailer.MailerService,
+	messagingProducer messagingproducer.MessagingProducerService,
 ) AuthUseCase {
 	return &authUseCaseImplementation{
-		userQuery:   userQuery,
-		regionQuery: regionQuery,
+		authQuery:         authQuery,
+		auditQuery:        auditQuery,
+		userQuery:         userQuery,
+		regionQuery:       regionQuery,
+		notifier:          notifier,
+		smsSender:         smsSender,
+		mailer:            mailer,
+		messagingProducer: messagingProducer,
 	}
 }
 
 func (q *authUseCaseImplementation) Login(ctx context.Context, roleIDs []int64, request authModel.LoginRequest) (response authModel.LoginResponse, err error) {
 	ctxt := "AuthUseCase-Login"
+	loginFailureKeys := getLoginFailureKeys(request)
+	if err = q.checkLoginFailures(ctx, loginFailureKeys); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckLoginFailures")
+		q.publishLoginAttempt(ctx, roleIDs, request, false, err.Error())
+		return
+	}
 	users, err := q.userQuery.FindUsers(
 		ctx,
 		userModel.UserFilter{
@@ -49,6 +82,8 @@ func (q *authUseCaseImplementation) Login(ctx context.Context, roleIDs []int64,
 	}
 	if len(users) == 0 {
 		err = authModel.ErrLoginFailed
+		q.recordLoginFailure(ctx, loginFailureKeys)
+		q.publishLoginAttempt(ctx, roleIDs, request, false, "user not found")
 		return
 	}
 	user := users[0]
@@ -57,25 +92,31 @@ func (q *authUseCaseImplementation) Login(ctx context.Context, roleIDs []int64,
 	if err = bcrypt.CompareHashAndPassword(encryptedPassword, password); err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCompareHashAndPassword")
 		err = authModel.ErrLoginFailed
+		q.recordLoginFailure(ctx, loginFailureKeys)
+		q.publishLoginAttempt(ctx, roleIDs, request, false, "invalid password")
 		return
 	}
-	expiryTime := time.Now().Add(time.Hour * 72).Unix()
-	claims := jwt.MapClaims{
-		"id":  user.ID,
-		"exp": expiryTime,
+	if slices.Contains(adminRoleIDs, user.Role.ID) {
+		if err = q.verifySecondFactor(ctx, user.ID, request.TOTPCode, request.RecoveryCode); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifySecondFactor")
+			// a missing code is the normal first step of a 2FA login, only wrong codes count as failures
+			if errors.Is(err, authModel.ErrInvalidTOTP) {
+				q.recordLoginFailure(ctx, loginFailureKeys)
+				q.publishLoginAttempt(ctx, roleIDs, request, false, "invalid second factor")
+			}
+			return
+		}
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	privateKey, err := keys.InitPrivateKey()
-	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrInitPrivateKey")
+	q.rehashPassword(ctx, user, password)
+	// only the identifier counter is cleared, a shared IP keeps its history
+	if err = q.authQuery.ClearLoginFailures(ctx, loginFailureKeys[0].key); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrClearLoginFailures")
 		return
 	}
-	if response.IDToken, err = token.SignedString(privateKey); err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSignedString")
-		return
+	q.publishLoginAttempt(ctx, roleIDs, request, true, "")
+	if response, err = q.createSession(ctx, user, request.SessionClient); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateSession")
 	}
-	response.ExpiresIn = expiryTime
-	response.Profile = user
 	return
 }
 
@@ -91,20 +132,24 @@ func (q *authUseCaseImplementation) ChangePassword(ctx context.Context, userID i
 	if err = bcrypt.CompareHashAndPassword(encryptedOldPasswordByte, newPassword); err == nil {
 		return errors.New("reusing old password is prohibited")
 	}
-	encryptedNewPassword, err := bcrypt.GenerateFromPassword(newPassword, bcrypt.DefaultCost)
+	encryptedNewPassword, err := bcrypt.GenerateFromPassword(newPassword, config.GetBcryptCost())
 	if err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateFromPassword")
 		return
 	}
 	if err = q.userQuery.ChangePassword(ctx, userID, helper.ByteSlice2String(encryptedNewPassword)); err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
+		return
+	}
+	if err = q.authQuery.RevokeSessions(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSessions")
 	}
 	return
 }
 
 func (q *authUseCaseImplementation) Register(ctx context.Context, request authModel.RegisterRequest) (response *authModel.RegisterResponse, err error) {
 	ctxt := "AuthUseCase-Register"
-	encryptedNewPassword, err := bcrypt.GenerateFromPassword(helper.String2ByteSlice(request.Password), bcrypt.DefaultCost)
+	encryptedNewPassword, err := bcrypt.GenerateFromPassword(helper.String2ByteSlice(request.Password), config.GetBcryptCost())
 	if err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateFromPassword")
 		return
@@ -120,13 +165,102 @@ func (q *authUseCaseImplementation) Register(ctx context.Context, request authMo
 		return
 	}
 	request.SubdistrictID = village.SubdistrictID
+	request.ActivationTokenExpiresAt = time.Now().Add(config.GetActivationTokenTTL())
 	if response, err = q.userQuery.Register(ctx, request); err != nil {
 		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRegister")
+		return
+	}
+	if !config.IsActivationTokenEnabled() {
+		response.ActivationToken = ""
+	}
+	// the account already exists at this point, a failed delivery is recovered through the resend endpoint
+	otp, errSend := q.sendActivationOTP(ctx, response.UserID, request.MobilePhone)
+	if errSend != nil {
+		helper.Log(ctx, zap.ErrorLevel, errSend.Error(), ctxt, "ErrSendActivationOTP")
+		return
+	}
+	response.OTPExpiresIn = otp.ExpiresAt.Unix()
+	return
+}
+
+func (q *authUseCaseImplementation) VerifyActivation(ctx context.Context, roleID int64, request authModel.ActivateRequest) (response authModel.LoginResponse, err error) {
+	ctxt := "AuthUseCase-VerifyActivation"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      []int64{roleID},
+			Status:       []int{userModel.StatusHold},
+			MobilePhones: []string{request.MobilePhone},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = authModel.ErrActivationFailed
+		return
+	}
+	user := users[0]
+	if _, err = q.verifyOTP(ctx, user.ID, authModel.OTPPurposeActivation, request.OTP); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyOTP")
+		return
+	}
+	userID, err := q.userQuery.ActivateByID(ctx, user.ID, user.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivateByID")
+		return
+	}
+	if userID == 0 {
+		err = authModel.ErrActivationFailed
+		return
+	}
+	user.Status = userModel.StatusActive
+	if response, err = q.createSession(ctx, user, request.SessionClient); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateSession")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) ResendActivation(ctx context.Context, roleID int64, request authModel.ResendActivationRequest) (response authModel.ResendActivationResponse, err error) {
+	ctxt := "AuthUseCase-ResendActivation"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      []int64{roleID},
+			Status:       []int{userModel.StatusHold},
+			MobilePhones: []string{request.MobilePhone},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
 	}
+	// registration already tells whether a mobile phone is taken, so there is nothing to hide here
+	if len(users) == 0 {
+		err = errors.New("no pending registration for this mobile phone")
+		return
+	}
+	user := users[0]
+	otp, err := q.sendActivationOTP(ctx, user.ID, user.MobilePhone)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSendActivationOTP")
+		return
+	}
+	response.OTPExpiresIn = otp.ExpiresAt.Unix()
+	if !config.IsActivationTokenEnabled() {
+		return
+	}
+	activationToken := helper.GenerateRandomString(32)
+	if err = q.userQuery.SetActivationToken(ctx, user.ID, activationToken, time.Now().Add(config.GetActivationTokenTTL())); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSetActivationToken")
+		return
+	}
+	response.ActivationToken = activationToken
 	return
 }
 
-func (q *authUseCaseImplementation) Activate(ctx context.Context, roleID int64, activationToken string) (response authModel.LoginResponse, err error) {
+func (q *authUseCaseImplementation) Activate(ctx context.Context, roleID int64, activationToken string, client authModel.SessionClient) (response authModel.LoginResponse, err error) {
 	ctxt := "AuthUseCase-Login"
 	userID, err := q.userQuery.Activate(ctx, roleID, activationToken)
 	if err != nil {
@@ -151,23 +285,297 @@ func (q *authUseCaseImplementation) Activate(ctx context.Context, roleID int64,
 		err = authModel.ErrActivationFailed
 		return
 	}
+	if response, err = q.createSession(ctx, users[0], client); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateSession")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) ForgotPassword(ctx context.Context, roleIDs []int64, request authModel.ForgotPasswordRequest) (err error) {
+	ctxt := "AuthUseCase-ForgotPassword"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      roleIDs,
+			Status:       []int{userModel.StatusActive},
+			MobilePhones: []string{request.MobilePhone},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	// unknown mobile phones are silently ignored so the endpoint can't be used to enumerate users
+	if len(users) == 0 {
+		return
+	}
 	user := users[0]
-	expiryTime := time.Now().Add(time.Hour * 72).Unix()
-	claims := jwt.MapClaims{
-		"id":  user.ID,
-		"exp": expiryTime,
+	passwordResetToken := helper.GenerateRandomString(32)
+	expiresAt := time.Now().Add(config.GetPasswordResetTokenTTL())
+	if err = q.userQuery.SetPasswordResetToken(ctx, user.ID, helper.HashToken(passwordResetToken), expiresAt); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSetPasswordResetToken")
+		return
+	}
+	message := fmt.Sprintf(
+		"Your laukpauk password reset token is %s. It expires at %s.",
+		passwordResetToken,
+		expiresAt.Format(time.RFC3339),
+	)
+	if err = q.notifier.Notify(ctx, user.MobilePhone, message); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrNotify")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) ResetPassword(ctx context.Context, roleIDs []int64, request authModel.ResetPasswordRequest) (err error) {
+	ctxt := "AuthUseCase-ResetPassword"
+	encryptedNewPassword, err := bcrypt.GenerateFromPassword(helper.String2ByteSlice(request.NewPassword), config.GetBcryptCost())
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateFromPassword")
+		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	privateKey, err := keys.InitPrivateKey()
+	userID, err := q.userQuery.ResetPassword(ctx, roleIDs, helper.HashToken(request.PasswordResetToken), helper.ByteSlice2String(encryptedNewPassword))
 	if err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrInitPrivateKey")
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResetPassword")
+		return
+	}
+	if userID == 0 {
+		err = authModel.ErrPasswordResetFailed
+		return
+	}
+	if err = q.authQuery.RevokeSessions(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSessions")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) RefreshToken(ctx context.Context, roleIDs []int64, request authModel.RefreshTokenRequest) (response authModel.LoginResponse, err error) {
+	ctxt := "AuthUseCase-RefreshToken"
+	refreshToken, err := q.authQuery.FindRefreshToken(ctx, helper.HashToken(request.RefreshToken))
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindRefreshToken")
+		return
+	}
+	if refreshToken == nil {
+		err = authModel.ErrInvalidRefreshToken
 		return
 	}
-	if response.IDToken, err = token.SignedString(privateKey); err != nil {
-		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSignedString")
+	now := time.Now()
+	if refreshToken.Session.RevokedAt != nil || refreshToken.ExpiresAt.Before(now) {
+		err = authModel.ErrInvalidRefreshToken
 		return
 	}
-	response.ExpiresIn = expiryTime
+	if refreshToken.UsedAt != nil {
+		// a rotated refresh token is being replayed, assume it leaked and revoke the whole family
+		if err = q.authQuery.RevokeSessions(ctx, refreshToken.UserID, refreshToken.SessionID); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSessions")
+			return
+		}
+		err = authModel.ErrInvalidRefreshToken
+		return
+	}
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{refreshToken.UserID},
+			RoleIDs: roleIDs,
+			Status:  []int{userModel.StatusActive},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = authModel.ErrInvalidRefreshToken
+		return
+	}
+	newRefreshToken := helper.GenerateRandomString(64)
+	refreshTokenExpiryTime := now.Add(config.GetRefreshTokenTTL())
+	if err = q.authQuery.RotateRefreshToken(ctx, *refreshToken, request.SessionClient, helper.HashToken(newRefreshToken), refreshTokenExpiryTime); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRotateRefreshToken")
+		return
+	}
+	if response, err = q.generateAccessToken(ctx, users[0], refreshToken.SessionID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateAccessToken")
+		return
+	}
+	response.RefreshToken = newRefreshToken
+	response.RefreshTokenExpiresIn = refreshTokenExpiryTime.Unix()
+	return
+}
+
+func (q *authUseCaseImplementation) Logout(ctx context.Context, currentUser authModel.CurrentUser) (err error) {
+	ctxt := "AuthUseCase-Logout"
+	if err = q.authQuery.RevokeSessions(ctx, currentUser.ID, currentUser.SessionID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSessions")
+		return
+	}
+	if err = q.authQuery.RevokeToken(ctx, currentUser.TokenID, currentUser.ExpiresAt); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeToken")
+		return
+	}
+	if currentUser.ImpersonatorID != 0 {
+		q.endImpersonation(ctx, currentUser)
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) FindSessions(ctx context.Context, currentUser authModel.CurrentUser) (response []authModel.Session, err error) {
+	ctxt := "AuthUseCase-FindSessions"
+	if response, err = q.authQuery.FindSessions(ctx, currentUser.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSessions")
+		return
+	}
+	for i := range response {
+		response[i].Current = response[i].ID == currentUser.SessionID
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) RevokeSession(ctx context.Context, currentUser authModel.CurrentUser, sessionID int64) (err error) {
+	ctxt := "AuthUseCase-RevokeSession"
+	if sessionID == currentUser.SessionID {
+		return q.Logout(ctx, currentUser)
+	}
+	sessions, err := q.authQuery.FindSessions(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSessions")
+		return
+	}
+	for _, session := range sessions {
+		if session.ID != sessionID {
+			continue
+		}
+		if err = q.authQuery.RevokeSessions(ctx, currentUser.ID, sessionID); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSessions")
+		}
+		return
+	}
+	err = authModel.ErrSessionNotFound
+	return
+}
+
+func (q *authUseCaseImplementation) RevokeOtherSessions(ctx context.Context, currentUser authModel.CurrentUser) (err error) {
+	ctxt := "AuthUseCase-RevokeOtherSessions"
+	if err = q.authQuery.RevokeOtherSessions(ctx, currentUser.ID, currentUser.SessionID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeOtherSessions")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) IsTokenRevoked(ctx context.Context, tokenID string, sessionID int64) (response bool, err error) {
+	ctxt := "AuthUseCase-IsTokenRevoked"
+	if response, err = q.authQuery.IsTokenRevoked(ctx, tokenID, sessionID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIsTokenRevoked")
+	}
+	return
+}
+
+// rehashPassword upgrades hashes made with a lower cost than configured, it runs on login
+// because that is the only time the plain password is known. Failures don't block the login.
+func (q *authUseCaseImplementation) rehashPassword(ctx context.Context, user userModel.User, password []byte) {
+	ctxt := "AuthUseCase-rehashPassword"
+	cost, err := bcrypt.Cost(helper.String2ByteSlice(user.Password))
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCost")
+		return
+	}
+	if cost >= config.GetBcryptCost() {
+		return
+	}
+	encryptedPassword, err := bcrypt.GenerateFromPassword(password, config.GetBcryptCost())
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateFromPassword")
+		return
+	}
+	if err = q.userQuery.ChangePassword(ctx, user.ID, helper.ByteSlice2String(encryptedPassword)); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangePassword")
+	}
+}
+
+func (q *authUseCaseImplementation) createSession(ctx context.Context, user userModel.User, client authModel.SessionClient) (response authModel.LoginResponse, err error) {
+	ctxt := "AuthUseCase-createSession"
+	refreshToken := helper.GenerateRandomString(64)
+	refreshTokenExpiryTime := time.Now().Add(config.GetRefreshTokenTTL())
+	session, err := q.authQuery.CreateSession(ctx, user.ID, client, helper.HashToken(refreshToken), refreshTokenExpiryTime)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateSession")
+		return
+	}
+	if response, err = q.generateAccessToken(ctx, user, session.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateAccessToken")
+		return
+	}
+	response.RefreshToken = refreshToken
+	response.RefreshTokenExpiresIn = refreshTokenExpiryTime.Unix()
+	return
+}
+
+func (q *authUseCaseImplementation) generateAccessToken(ctx context.Context, user userModel.User, sessionID int64) (response authModel.LoginResponse, err error) {
+	ctxt := "AuthUseCase-generateAccessToken"
+	totpPending, err := q.isTOTPPending(ctx, user)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIsTOTPPending")
+		return
+	}
+	now := time.Now()
+	expiryTime := now.Add(config.GetAccessTokenTTL())
+	claims := authModel.TokenClaims{
+		UserID:      user.ID,
+		RoleID:      user.Role.ID,
+		Status:      user.Status,
+		SessionID:   sessionID,
+		TOTPPending: totpPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        helper.GenerateRandomString(32),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiryTime),
+		},
+	}
+	if response.IDToken, err = keys.Sign(claims); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSign")
+		return
+	}
+	response.ExpiresIn = expiryTime.Unix()
 	response.Profile = user
 	return
 }
+
+func (q *authUseCaseImplementation) FindJWKS(ctx context.Context) (response *keys.JWKS, err error) {
+	ctxt := "AuthUseCase-FindJWKS"
+	if response, err = keys.GetJWKS(); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGetJWKS")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) UnlockLogin(ctx context.Context, userID int64) (err error) {
+	ctxt := "AuthUseCase-UnlockLogin"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{userID},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		return errors.New("user not found")
+	}
+	user := users[0]
+	identifiers := []string{user.MobilePhone}
+	if user.Email != nil {
+		identifiers = append(identifiers, *user.Email)
+	}
+	loginFailureKeys := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		loginFailureKeys[i] = getIdentifierLoginFailureKey(identifier)
+	}
+	if err = q.authQuery.ClearLoginFailures(ctx, loginFailureKeys...); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrClearLoginFailures")
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	auditModel "github.com/roysitumorang/laukpauk/modules/audit/model"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+// RequestEmailChange mails a code to the email being added, it only becomes a login identifier after ConfirmEmailChange
+func (q *authUseCaseImplementation) RequestEmailChange(ctx context.Context, currentUser authModel.CurrentUser, request authModel.EmailChangeRequest) (response authModel.EmailChangeResponse, err error) {
+	ctxt := "AuthUseCase-RequestEmailChange"
+	user, err := q.findActiveUser(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+		return
+	}
+	if user.EmailVerifiedAt != nil && user.Email != nil && *user.Email == request.Email {
+		err = errors.New("email is already verified")
+		return
+	}
+	if err = q.checkEmailAvailable(ctx, user.Role.ID, request.Email); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckEmailAvailable")
+		return
+	}
+	code, otp, err := q.createOTP(ctx, user.ID, authModel.OTPPurposeEmailChange, request.Email)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateOTP")
+		return
+	}
+	body := fmt.Sprintf(
+		"Hi %s,\n\nYour laukpauk email verification code is %s. It expires in %d minutes, never share it with anyone.\n\nIf you didn't ask for it, you can ignore this email.",
+		user.Name,
+		code,
+		int64(math.Ceil(config.GetOTPTTL().Minutes())),
+	)
+	if err = q.mailer.Send(ctx, request.Email, "Verify your laukpauk email", body); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSend")
+		return
+	}
+	response.OTPExpiresIn = otp.ExpiresAt.Unix()
+	return
+}
+
+// ConfirmEmailChange stores the email the code was mailed to as the verified email of the user
+func (q *authUseCaseImplementation) ConfirmEmailChange(ctx context.Context, currentUser authModel.CurrentUser, request authModel.ConfirmEmailChangeRequest) (response userModel.User, err error) {
+	ctxt := "AuthUseCase-ConfirmEmailChange"
+	user, err := q.findActiveUser(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+		return
+	}
+	otp, err := q.verifyOTP(ctx, user.ID, authModel.OTPPurposeEmailChange, request.OTP)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyOTP")
+		return
+	}
+	if err = q.checkEmailAvailable(ctx, user.Role.ID, otp.Recipient); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckEmailAvailable")
+		return
+	}
+	userID, err := q.userQuery.ChangeEmail(
+		ctx,
+		user.ID,
+		otp.Recipient,
+		auditModel.AuditLog{
+			ActorID: currentUser.ID,
+			Action:  auditModel.ActionEmailChanged,
+			Data: map[string]interface{}{
+				"old_email": user.Email,
+				"new_email": otp.Recipient,
+			},
+			IpAddress: request.IpAddress,
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangeEmail")
+		return
+	}
+	if userID == 0 {
+		err = errors.New("email change failed")
+		return
+	}
+	// a verified email is a login identifier, so its previous owner gets a heads up
+	if user.EmailVerifiedAt != nil && user.Email != nil && *user.Email != otp.Recipient {
+		body := fmt.Sprintf(
+			"Hi %s,\n\nThe email of your laukpauk account has been changed to %s. Contact us immediately if it wasn't you.",
+			user.Name,
+			otp.Recipient,
+		)
+		if errSend := q.mailer.Send(ctx, *user.Email, "Your laukpauk email has been changed", body); errSend != nil {
+			helper.Log(ctx, zap.ErrorLevel, errSend.Error(), ctxt, "ErrSend")
+		}
+	}
+	if response, err = q.findActiveUser(ctx, user.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) checkEmailAvailable(ctx context.Context, roleID int64, email string) (err error) {
+	ctxt := "AuthUseCase-checkEmailAvailable"
+	// MobilePhones matches verified emails too
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      []int64{roleID},
+			MobilePhones: []string{email},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) > 0 {
+		err = userModel.ErrEmailRegistered
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/keys"
+	auditModel "github.com/roysitumorang/laukpauk/modules/audit/model"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+var (
+	impersonatableRoleIDs = []int64{roleModel.RoleBuyer, roleModel.RoleSeller}
+)
+
+// Impersonate issues a short lived access token for a buyer or seller on behalf of a SuperAdmin.
+// It comes without a refresh token, but gets a session of its own so that suspending the user or
+// revoking their sessions ends it too, otherwise it simply expires, or ends earlier when logged out.
+// The session is kept out of the user's own session list and export.
+func (q *authUseCaseImplementation) Impersonate(ctx context.Context, impersonatorID, userID int64, ipAddress net.IP) (response authModel.LoginResponse, err error) {
+	ctxt := "AuthUseCase-Impersonate"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{userID},
+			RoleIDs: impersonatableRoleIDs,
+			Status:  []int{userModel.StatusActive},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = errors.New("active buyer or seller not found")
+		return
+	}
+	user := users[0]
+	now := time.Now()
+	expiryTime := now.Add(config.GetImpersonationTokenTTL())
+	session, err := q.authQuery.CreateImpersonationSession(
+		ctx,
+		user.ID,
+		impersonatorID,
+		authModel.SessionClient{
+			IpAddress: ipAddress,
+		},
+		expiryTime,
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateImpersonationSession")
+		return
+	}
+	claims := authModel.TokenClaims{
+		UserID:         user.ID,
+		RoleID:         user.Role.ID,
+		Status:         user.Status,
+		SessionID:      session.ID,
+		ImpersonatorID: impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        helper.GenerateRandomString(32),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiryTime),
+		},
+	}
+	// no audit record, no token
+	if _, err = q.auditQuery.CreateAuditLog(
+		ctx,
+		auditModel.AuditLog{
+			UserID:  user.ID,
+			ActorID: impersonatorID,
+			Action:  auditModel.ActionImpersonationStart,
+			Data: map[string]interface{}{
+				"token_id":   claims.ID,
+				"session_id": session.ID,
+				"expires_at": expiryTime.UTC(),
+			},
+			IpAddress: ipAddress,
+		},
+	); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateAuditLog")
+		if errRevoke := q.authQuery.RevokeSessions(ctx, user.ID, session.ID); errRevoke != nil {
+			helper.Log(ctx, zap.ErrorLevel, errRevoke.Error(), ctxt, "ErrRevokeSessions")
+		}
+		return
+	}
+	if response.IDToken, err = keys.Sign(claims); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSign")
+		return
+	}
+	response.ExpiresIn = expiryTime.Unix()
+	response.Profile = user
+	return
+}
+
+// endImpersonation records an impersonation ended by logout, tokens left to expire are covered
+// by the expires_at of their start record.
+func (q *authUseCaseImplementation) endImpersonation(ctx context.Context, currentUser authModel.CurrentUser) {
+	ctxt := "AuthUseCase-endImpersonation"
+	if _, err := q.auditQuery.CreateAuditLog(
+		ctx,
+		auditModel.AuditLog{
+			UserID:  currentUser.ID,
+			ActorID: currentUser.ImpersonatorID,
+			Action:  auditModel.ActionImpersonationEnd,
+			Data: map[string]interface{}{
+				"token_id": currentUser.TokenID,
+			},
+		},
+	); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateAuditLog")
+	}
+}
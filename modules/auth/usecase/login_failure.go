@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	"go.uber.org/zap"
+)
+
+const (
+	loginFailureBaseDelay = time.Second
+)
+
+type (
+	loginFailureKey struct {
+		key         string
+		maxFailures int
+	}
+)
+
+func getIdentifierLoginFailureKey(identifier string) string {
+	return fmt.Sprintf("identifier:%s", identifier)
+}
+
+func getLoginFailureKeys(request authModel.LoginRequest) []loginFailureKey {
+	response := []loginFailureKey{
+		{
+			key:         getIdentifierLoginFailureKey(request.MobilePhone),
+			maxFailures: config.GetLoginMaxFailures(),
+		},
+	}
+	// helper.GetIPAdress falls back to loopback when no proxy header is present,
+	// counting it would lock out everybody at once
+	if request.IpAddress != nil && !request.IpAddress.IsLoopback() && !request.IpAddress.IsUnspecified() {
+		response = append(
+			response,
+			loginFailureKey{
+				key:         fmt.Sprintf("ip:%s", request.IpAddress.String()),
+				maxFailures: config.GetLoginMaxFailuresPerIP(),
+			},
+		)
+	}
+	return response
+}
+
+func getLoginDelay(failedCount int) time.Duration {
+	maxDelay := config.GetLoginMaxDelay()
+	if failedCount < 1 {
+		return 0
+	}
+	if failedCount > 32 {
+		return maxDelay
+	}
+	delay := loginFailureBaseDelay << (failedCount - 1)
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+func (q *authUseCaseImplementation) checkLoginFailures(ctx context.Context, keys []loginFailureKey) (err error) {
+	ctxt := "AuthUseCase-checkLoginFailures"
+	params := make([]string, len(keys))
+	for i, key := range keys {
+		params[i] = key.key
+	}
+	loginFailures, err := q.authQuery.FindLoginFailures(ctx, params...)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindLoginFailures")
+		return
+	}
+	now := time.Now()
+	for _, loginFailure := range loginFailures {
+		if loginFailure.LockedUntil != nil && loginFailure.LockedUntil.After(now) {
+			return authModel.ErrLoginLocked
+		}
+		if retryAt := loginFailure.LastFailedAt.Add(getLoginDelay(loginFailure.FailedCount)); retryAt.After(now) {
+			return authModel.NewErrLoginThrottled(retryAt.Sub(now))
+		}
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) recordLoginFailure(ctx context.Context, keys []loginFailureKey) {
+	ctxt := "AuthUseCase-recordLoginFailure"
+	lockoutDuration := config.GetLoginLockoutDuration()
+	now := time.Now()
+	for _, key := range keys {
+		loginFailure, err := q.authQuery.RecordLoginFailure(ctx, key.key, now.Add(-lockoutDuration))
+		if err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRecordLoginFailure")
+			continue
+		}
+		if loginFailure.FailedCount < key.maxFailures {
+			continue
+		}
+		if err = q.authQuery.LockLogin(ctx, key.key, now.Add(lockoutDuration)); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrLockLogin")
+		}
+	}
+}
+
+func (q *authUseCaseImplementation) publishLoginAttempt(ctx context.Context, roleIDs []int64, request authModel.LoginRequest, success bool, reason string) {
+	ctxt := "AuthUseCase-publishLoginAttempt"
+	var ipAddress string
+	if request.IpAddress != nil {
+		ipAddress = request.IpAddress.String()
+	}
+	if err := q.messagingProducer.Publish(
+		config.TopicAuth,
+		map[string]interface{}{
+			"event":        "login.attempt",
+			"role_ids":     roleIDs,
+			"mobile_phone": request.MobilePhone,
+			"ip_address":   ipAddress,
+			"success":      success,
+			"reason":       reason,
+			"created_at":   time.Now().UTC(),
+		},
+	); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrPublish")
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+// RequestLoginOTP texts a login code to an active user, unknown mobile phones get the same
+// response without a code so the endpoint can't be used to enumerate users. For the same reason
+// throttled resends and failed sends are only logged, they'd otherwise tell registered phones apart.
+func (q *authUseCaseImplementation) RequestLoginOTP(ctx context.Context, roleIDs []int64, request authModel.LoginOTPRequest) (response authModel.LoginOTPResponse, err error) {
+	ctxt := "AuthUseCase-RequestLoginOTP"
+	loginRequest := authModel.LoginRequest{
+		MobilePhone: request.MobilePhone,
+		SessionClient: authModel.SessionClient{
+			IpAddress: request.IpAddress,
+		},
+	}
+	if err = q.checkLoginFailures(ctx, getLoginFailureKeys(loginRequest)); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckLoginFailures")
+		return
+	}
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      roleIDs,
+			Status:       []int{userModel.StatusActive},
+			MobilePhones: []string{request.MobilePhone},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	response.OTPExpiresIn = time.Now().Add(config.GetOTPTTL()).Unix()
+	if len(users) == 0 {
+		return
+	}
+	user := users[0]
+	code, otp, errCreateOTP := q.createOTP(ctx, user.ID, authModel.OTPPurposeLogin, user.MobilePhone)
+	if errCreateOTP != nil {
+		helper.Log(ctx, zap.ErrorLevel, errCreateOTP.Error(), ctxt, "ErrCreateOTP")
+		return
+	}
+	message := fmt.Sprintf(
+		"Your laukpauk login code is %s. It expires in %d minutes, never share it with anyone.",
+		code,
+		int64(math.Ceil(config.GetOTPTTL().Minutes())),
+	)
+	if errSend := q.smsSender.Send(ctx, user.MobilePhone, message); errSend != nil {
+		helper.Log(ctx, zap.ErrorLevel, errSend.Error(), ctxt, "ErrSend")
+		return
+	}
+	response.OTPExpiresIn = otp.ExpiresAt.Unix()
+	return
+}
+
+// VerifyLoginOTP is Login with a texted code in place of the password, wrong codes count
+// towards both the code attempt limit and the login lockout.
+func (q *authUseCaseImplementation) VerifyLoginOTP(ctx context.Context, roleIDs []int64, request authModel.VerifyLoginOTPRequest) (response authModel.LoginResponse, err error) {
+	ctxt := "AuthUseCase-VerifyLoginOTP"
+	loginRequest := authModel.LoginRequest{
+		MobilePhone:   request.MobilePhone,
+		SessionClient: request.SessionClient,
+	}
+	loginFailureKeys := getLoginFailureKeys(loginRequest)
+	if err = q.checkLoginFailures(ctx, loginFailureKeys); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckLoginFailures")
+		q.publishLoginAttempt(ctx, roleIDs, loginRequest, false, err.Error())
+		return
+	}
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      roleIDs,
+			Status:       []int{userModel.StatusActive},
+			MobilePhones: []string{request.MobilePhone},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = authModel.ErrInvalidOTP
+		q.recordLoginFailure(ctx, loginFailureKeys)
+		q.publishLoginAttempt(ctx, roleIDs, loginRequest, false, "user not found")
+		return
+	}
+	user := users[0]
+	if _, err = q.verifyOTP(ctx, user.ID, authModel.OTPPurposeLogin, request.OTP); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyOTP")
+		if errors.Is(err, authModel.ErrInvalidOTP) || errors.Is(err, authModel.ErrOTPAttemptsExceeded) {
+			q.recordLoginFailure(ctx, loginFailureKeys)
+			q.publishLoginAttempt(ctx, roleIDs, loginRequest, false, "invalid otp")
+		}
+		return
+	}
+	if err = q.authQuery.ClearLoginFailures(ctx, loginFailureKeys[0].key); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrClearLoginFailures")
+		return
+	}
+	q.publishLoginAttempt(ctx, roleIDs, loginRequest, true, "")
+	if response, err = q.createSession(ctx, user, request.SessionClient); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateSession")
+	}
+	return
+}
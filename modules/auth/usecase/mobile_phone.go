@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	auditModel "github.com/roysitumorang/laukpauk/modules/audit/model"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+// RequestMobilePhoneChange sends a code to the new mobile phone, the change itself waits for ConfirmMobilePhoneChange
+func (q *authUseCaseImplementation) RequestMobilePhoneChange(ctx context.Context, currentUser authModel.CurrentUser, request authModel.MobilePhoneChangeRequest) (response authModel.MobilePhoneChangeResponse, err error) {
+	ctxt := "AuthUseCase-RequestMobilePhoneChange"
+	user, err := q.findActiveUser(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+		return
+	}
+	if request.MobilePhone == user.MobilePhone {
+		err = errors.New("new mobile phone is the same as the current one")
+		return
+	}
+	if err = q.checkMobilePhoneAvailable(ctx, user.Role.ID, request.MobilePhone); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckMobilePhoneAvailable")
+		return
+	}
+	code, otp, err := q.createOTP(ctx, user.ID, authModel.OTPPurposeMobilePhoneChange, request.MobilePhone)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateOTP")
+		return
+	}
+	message := fmt.Sprintf(
+		"Your laukpauk mobile phone change code is %s. It expires in %d minutes, never share it with anyone.",
+		code,
+		int64(math.Ceil(config.GetOTPTTL().Minutes())),
+	)
+	if err = q.smsSender.Send(ctx, request.MobilePhone, message); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSend")
+		return
+	}
+	response.OTPExpiresIn = otp.ExpiresAt.Unix()
+	return
+}
+
+// ConfirmMobilePhoneChange commits the number the code was sent to, which is read back from the code itself
+// so it can't be swapped between the request and the confirmation.
+func (q *authUseCaseImplementation) ConfirmMobilePhoneChange(ctx context.Context, currentUser authModel.CurrentUser, request authModel.ConfirmMobilePhoneChangeRequest) (response userModel.User, err error) {
+	ctxt := "AuthUseCase-ConfirmMobilePhoneChange"
+	user, err := q.findActiveUser(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveUser")
+		return
+	}
+	otp, err := q.verifyOTP(ctx, user.ID, authModel.OTPPurposeMobilePhoneChange, request.OTP)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifyOTP")
+		return
+	}
+	// the number may have been taken by someone else since the code was sent
+	if err = q.checkMobilePhoneAvailable(ctx, user.Role.ID, otp.Recipient); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckMobilePhoneAvailable")
+		return
+	}
+	userID, err := q.userQuery.ChangeMobilePhone(
+		ctx,
+		user.ID,
+		otp.Recipient,
+		auditModel.AuditLog{
+			ActorID: currentUser.ID,
+			Action:  auditModel.ActionMobilePhoneChanged,
+			Data: map[string]interface{}{
+				"old_mobile_phone": user.MobilePhone,
+				"new_mobile_phone": otp.Recipient,
+			},
+			IpAddress: request.IpAddress,
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrChangeMobilePhone")
+		return
+	}
+	if userID == 0 {
+		err = errors.New("mobile phone change failed")
+		return
+	}
+	// warn the previous number in case the account was taken over
+	message := fmt.Sprintf(
+		"The mobile phone of your laukpauk account has been changed to %s. Contact us immediately if it wasn't you.",
+		otp.Recipient,
+	)
+	if errNotify := q.notifier.Notify(ctx, user.MobilePhone, message); errNotify != nil {
+		helper.Log(ctx, zap.ErrorLevel, errNotify.Error(), ctxt, "ErrNotify")
+	}
+	response = user
+	response.MobilePhone = otp.Recipient
+	return
+}
+
+func (q *authUseCaseImplementation) findActiveUser(ctx context.Context, userID int64) (response userModel.User, err error) {
+	ctxt := "AuthUseCase-findActiveUser"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{userID},
+			Status:  []int{userModel.StatusActive},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = errors.New("user not found")
+		return
+	}
+	response = users[0]
+	return
+}
+
+func (q *authUseCaseImplementation) checkMobilePhoneAvailable(ctx context.Context, roleID int64, mobilePhone string) (err error) {
+	ctxt := "AuthUseCase-checkMobilePhoneAvailable"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			RoleIDs:      []int64{roleID},
+			MobilePhones: []string{mobilePhone},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) > 0 {
+		err = userModel.ErrMobilePhoneRegistered
+	}
+	return
+}
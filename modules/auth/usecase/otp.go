@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	"go.uber.org/zap"
+)
+
+const (
+	otpLength = 6
+)
+
+// hashOTP salts the code with its owner and purpose, six digits alone would be trivial to reverse
+func hashOTP(userID int64, purpose, code string) string {
+	return helper.HashToken(fmt.Sprintf("%d:%s:%s", userID, purpose, code))
+}
+
+// createOTP stores a fresh code and returns it in plain text for the caller to deliver
+func (q *authUseCaseImplementation) createOTP(ctx context.Context, userID int64, purpose, recipient string) (code string, response *authModel.OTP, err error) {
+	ctxt := "AuthUseCase-createOTP"
+	latestOTP, err := q.authQuery.FindLatestOTP(ctx, userID, purpose)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindLatestOTP")
+		return
+	}
+	if latestOTP != nil {
+		if retryIn := time.Until(latestOTP.CreatedAt.Add(config.GetOTPResendInterval())); retryIn > 0 {
+			err = authModel.NewErrOTPResendTooSoon(retryIn)
+			return
+		}
+	}
+	// the per account interval alone would let one phone be flooded through several accounts or purposes
+	now := time.Now()
+	sentTimes, err := q.authQuery.FindOTPSentTimes(ctx, recipient, now.Add(-time.Hour))
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindOTPSentTimes")
+		return
+	}
+	if maxPerHour := config.GetOTPMaxPerHour(); len(sentTimes) >= maxPerHour {
+		err = authModel.NewErrOTPResendTooSoon(sentTimes[len(sentTimes)-maxPerHour].Add(time.Hour).Sub(now))
+		return
+	}
+	code = helper.GenerateRandomDigits(otpLength)
+	if response, err = q.authQuery.CreateOTP(ctx, userID, purpose, recipient, hashOTP(userID, purpose, code), now.Add(config.GetOTPTTL())); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateOTP")
+	}
+	return
+}
+
+// verifyOTP consumes the latest code of the given purpose, every mismatch counts towards the attempt limit
+func (q *authUseCaseImplementation) verifyOTP(ctx context.Context, userID int64, purpose, code string) (response *authModel.OTP, err error) {
+	ctxt := "AuthUseCase-verifyOTP"
+	otp, err := q.authQuery.FindLatestOTP(ctx, userID, purpose)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindLatestOTP")
+		return
+	}
+	if otp == nil || otp.ConsumedAt != nil || otp.ExpiresAt.Before(time.Now()) {
+		err = authModel.ErrInvalidOTP
+		return
+	}
+	maxAttempts := config.GetOTPMaxAttempts()
+	if otp.Attempts >= maxAttempts {
+		err = authModel.ErrOTPAttemptsExceeded
+		return
+	}
+	if subtle.ConstantTimeCompare(helper.String2ByteSlice(hashOTP(userID, purpose, code)), helper.String2ByteSlice(otp.CodeHash)) != 1 {
+		if err = q.authQuery.IncrementOTPAttempts(ctx, otp.ID); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIncrementOTPAttempts")
+			return
+		}
+		err = authModel.ErrInvalidOTP
+		if otp.Attempts+1 >= maxAttempts {
+			err = authModel.ErrOTPAttemptsExceeded
+		}
+		return
+	}
+	ok, err := q.authQuery.ConsumeOTP(ctx, otp.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConsumeOTP")
+		return
+	}
+	if !ok {
+		err = authModel.ErrInvalidOTP
+		return
+	}
+	response = otp
+	return
+}
+
+func (q *authUseCaseImplementation) sendActivationOTP(ctx context.Context, userID int64, mobilePhone string) (response *authModel.OTP, err error) {
+	ctxt := "AuthUseCase-sendActivationOTP"
+	code, response, err := q.createOTP(ctx, userID, authModel.OTPPurposeActivation, mobilePhone)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateOTP")
+		return
+	}
+	message := fmt.Sprintf(
+		"Your laukpauk activation code is %s. It expires in %d minutes, never share it with anyone.",
+		code,
+		int64(math.Ceil(config.GetOTPTTL().Minutes())),
+	)
+	if err = q.smsSender.Send(ctx, mobilePhone, message); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSend")
+	}
+	return
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+const (
+	totpIssuer             = "laukpauk"
+	recoveryCodeCount      = 10
+	recoveryCodeHalfLength = 5
+)
+
+var (
+	adminRoleIDs = []int64{roleModel.RoleSuperAdmin, roleModel.RoleAdmin}
+)
+
+// normalizeRecoveryCode lets users type recovery codes with or without the dash and in any case
+func normalizeRecoveryCode(recoveryCode string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(recoveryCode), "-", ""))
+}
+
+func (q *authUseCaseImplementation) isTOTPMandatory(ctx context.Context) (response bool, err error) {
+	ctxt := "AuthUseCase-isTOTPMandatory"
+	value, err := q.authQuery.FindSetting(ctx, authModel.SettingAdminTOTPMandatory)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSetting")
+		return
+	}
+	if value != nil {
+		response, _ = strconv.ParseBool(*value)
+	}
+	return
+}
+
+// isTOTPPending tells whether an admin has to enrol TOTP before the token grants anything else
+func (q *authUseCaseImplementation) isTOTPPending(ctx context.Context, user userModel.User) (response bool, err error) {
+	ctxt := "AuthUseCase-isTOTPPending"
+	if !slices.Contains(adminRoleIDs, user.Role.ID) {
+		return
+	}
+	mandatory, err := q.isTOTPMandatory(ctx)
+	if err != nil || !mandatory {
+		return
+	}
+	totp, err := q.authQuery.FindTOTP(ctx, user.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindTOTP")
+		return
+	}
+	response = totp == nil || totp.ConfirmedAt == nil
+	return
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code, users without a confirmed enrolment pass through.
+func (q *authUseCaseImplementation) verifySecondFactor(ctx context.Context, userID int64, totpCode, recoveryCode string) (err error) {
+	ctxt := "AuthUseCase-verifySecondFactor"
+	totp, err := q.authQuery.FindTOTP(ctx, userID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindTOTP")
+		return
+	}
+	if totp == nil || totp.ConfirmedAt == nil {
+		return
+	}
+	var ok bool
+	switch {
+	case totpCode != "":
+		step, valid := helper.ValidateTOTP(totp.Secret, totpCode, time.Now())
+		if !valid {
+			return authModel.ErrInvalidTOTP
+		}
+		// a code is only good once, otherwise an observed code could be replayed within its window
+		if ok, err = q.authQuery.UseTOTPStep(ctx, userID, step); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUseTOTPStep")
+			return
+		}
+	case recoveryCode != "":
+		if ok, err = q.authQuery.UseRecoveryCode(ctx, userID, helper.HashToken(normalizeRecoveryCode(recoveryCode))); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUseRecoveryCode")
+			return
+		}
+	default:
+		return authModel.ErrTOTPRequired
+	}
+	if !ok {
+		err = authModel.ErrInvalidTOTP
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) EnrolTOTP(ctx context.Context, userID int64) (response authModel.TOTPEnrolment, err error) {
+	ctxt := "AuthUseCase-EnrolTOTP"
+	totp, err := q.authQuery.FindTOTP(ctx, userID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindTOTP")
+		return
+	}
+	if totp != nil && totp.ConfirmedAt != nil {
+		err = authModel.ErrTOTPEnrolled
+		return
+	}
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{userID},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = authModel.ErrTOTPNotEnrolled
+		return
+	}
+	if response.Secret, err = helper.GenerateTOTPSecret(); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrGenerateTOTPSecret")
+		return
+	}
+	if err = q.authQuery.SaveTOTPSecret(ctx, userID, response.Secret); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSaveTOTPSecret")
+		return
+	}
+	issuer := config.AppName
+	if issuer == "" {
+		issuer = totpIssuer
+	}
+	response.ProvisioningURI = helper.GetTOTPProvisioningURI(issuer, users[0].MobilePhone, response.Secret)
+	return
+}
+
+func (q *authUseCaseImplementation) ConfirmTOTP(ctx context.Context, userID int64, request authModel.TOTPCodeRequest) (response authModel.TOTPRecoveryCodes, err error) {
+	ctxt := "AuthUseCase-ConfirmTOTP"
+	totp, err := q.authQuery.FindTOTP(ctx, userID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindTOTP")
+		return
+	}
+	if totp == nil {
+		err = authModel.ErrTOTPNotEnrolled
+		return
+	}
+	if totp.ConfirmedAt != nil {
+		err = authModel.ErrTOTPEnrolled
+		return
+	}
+	step, ok := helper.ValidateTOTP(totp.Secret, request.Code, time.Now())
+	if !ok {
+		err = authModel.ErrInvalidTOTP
+		return
+	}
+	response.RecoveryCodes = make([]string, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, recoveryCodeCount)
+	for i := range response.RecoveryCodes {
+		recoveryCode := helper.GenerateRandomString(recoveryCodeHalfLength * 2)
+		response.RecoveryCodes[i] = recoveryCode[:recoveryCodeHalfLength] + "-" + recoveryCode[recoveryCodeHalfLength:]
+		recoveryCodeHashes[i] = helper.HashToken(recoveryCode)
+	}
+	if err = q.authQuery.ConfirmTOTP(ctx, userID, step, recoveryCodeHashes); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrConfirmTOTP")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) DisableTOTP(ctx context.Context, userID int64, request authModel.TOTPCodeRequest) (err error) {
+	ctxt := "AuthUseCase-DisableTOTP"
+	mandatory, err := q.isTOTPMandatory(ctx)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIsTOTPMandatory")
+		return
+	}
+	if mandatory {
+		return authModel.ErrTOTPMandatory
+	}
+	totp, err := q.authQuery.FindTOTP(ctx, userID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindTOTP")
+		return
+	}
+	if totp == nil {
+		return authModel.ErrTOTPNotEnrolled
+	}
+	if err = q.verifySecondFactor(ctx, userID, request.Code, request.RecoveryCode); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrVerifySecondFactor")
+		return
+	}
+	if err = q.authQuery.DeleteTOTP(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrDeleteTOTP")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) FindTOTPPolicy(ctx context.Context) (response authModel.TOTPPolicy, err error) {
+	ctxt := "AuthUseCase-FindTOTPPolicy"
+	if response.Mandatory, err = q.isTOTPMandatory(ctx); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIsTOTPMandatory")
+	}
+	return
+}
+
+func (q *authUseCaseImplementation) SaveTOTPPolicy(ctx context.Context, request authModel.TOTPPolicy, updatedBy int64) (err error) {
+	ctxt := "AuthUseCase-SaveTOTPPolicy"
+	if err = q.authQuery.SaveSetting(ctx, authModel.SettingAdminTOTPMandatory, strconv.FormatBool(request.Mandatory), updatedBy); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSaveSetting")
+	}
+	return
+}
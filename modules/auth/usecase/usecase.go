@@ -2,15 +2,46 @@ package usecase
 
 import (
 	"context"
+	"net"
 
+	"github.com/roysitumorang/laukpauk/keys"
 	"github.com/roysitumorang/laukpauk/modules/auth/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
 )
 
 type (
 	AuthUseCase interface {
 		Login(ctx context.Context, roleIDs []int64, request model.LoginRequest) (response model.LoginResponse, err error)
+		RequestLoginOTP(ctx context.Context, roleIDs []int64, request model.LoginOTPRequest) (response model.LoginOTPResponse, err error)
+		VerifyLoginOTP(ctx context.Context, roleIDs []int64, request model.VerifyLoginOTPRequest) (response model.LoginResponse, err error)
 		ChangePassword(ctx context.Context, userID int64, encryptedPassword string, request model.ChangePassword) (err error)
 		Register(ctx context.Context, request model.RegisterRequest) (response *model.RegisterResponse, err error)
-		Activate(ctx context.Context, roleID int64, activationToken string) (response model.LoginResponse, err error)
+		Activate(ctx context.Context, roleID int64, activationToken string, client model.SessionClient) (response model.LoginResponse, err error)
+		VerifyActivation(ctx context.Context, roleID int64, request model.ActivateRequest) (response model.LoginResponse, err error)
+		ResendActivation(ctx context.Context, roleID int64, request model.ResendActivationRequest) (response model.ResendActivationResponse, err error)
+		ForgotPassword(ctx context.Context, roleIDs []int64, request model.ForgotPasswordRequest) (err error)
+		ResetPassword(ctx context.Context, roleIDs []int64, request model.ResetPasswordRequest) (err error)
+		RefreshToken(ctx context.Context, roleIDs []int64, request model.RefreshTokenRequest) (response model.LoginResponse, err error)
+		Logout(ctx context.Context, currentUser model.CurrentUser) (err error)
+		FindSessions(ctx context.Context, currentUser model.CurrentUser) (response []model.Session, err error)
+		RevokeSession(ctx context.Context, currentUser model.CurrentUser, sessionID int64) (err error)
+		RevokeOtherSessions(ctx context.Context, currentUser model.CurrentUser) (err error)
+		IsTokenRevoked(ctx context.Context, tokenID string, sessionID int64) (response bool, err error)
+		RequestMobilePhoneChange(ctx context.Context, currentUser model.CurrentUser, request model.MobilePhoneChangeRequest) (response model.MobilePhoneChangeResponse, err error)
+		ConfirmMobilePhoneChange(ctx context.Context, currentUser model.CurrentUser, request model.ConfirmMobilePhoneChangeRequest) (response userModel.User, err error)
+		RequestEmailChange(ctx context.Context, currentUser model.CurrentUser, request model.EmailChangeRequest) (response model.EmailChangeResponse, err error)
+		ConfirmEmailChange(ctx context.Context, currentUser model.CurrentUser, request model.ConfirmEmailChangeRequest) (response userModel.User, err error)
+		Impersonate(ctx context.Context, impersonatorID, userID int64, ipAddress net.IP) (response model.LoginResponse, err error)
+		UpdateProfile(ctx context.Context, currentUser model.CurrentUser, request model.UpdateProfileRequest) (response userModel.User, err error)
+		ExportAccount(ctx context.Context, currentUser model.CurrentUser) (response model.AccountExport, err error)
+		RequestAccountDeletion(ctx context.Context, currentUser model.CurrentUser) (response model.AccountDeletion, err error)
+		CancelAccountDeletion(ctx context.Context, currentUser model.CurrentUser) (err error)
+		FindJWKS(ctx context.Context) (response *keys.JWKS, err error)
+		UnlockLogin(ctx context.Context, userID int64) (err error)
+		EnrolTOTP(ctx context.Context, userID int64) (response model.TOTPEnrolment, err error)
+		ConfirmTOTP(ctx context.Context, userID int64, request model.TOTPCodeRequest) (response model.TOTPRecoveryCodes, err error)
+		DisableTOTP(ctx context.Context, userID int64, request model.TOTPCodeRequest) (err error)
+		FindTOTPPolicy(ctx context.Context) (response model.TOTPPolicy, err error)
+		SaveTOTPPolicy(ctx context.Context, request model.TOTPPolicy, updatedBy int64) (err error)
 	}
 )
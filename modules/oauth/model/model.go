@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/errors"
+)
+
+const (
+	ClientIDPrefix = "lpc_"
+	TokenType      = "Bearer"
+
+	GrantTypeClientCredentials = "client_credentials"
+
+	CurrentClientContextKey = "current_client"
+
+	ScopeProductsRead = "products:read"
+	ScopeOrdersRead   = "orders:read"
+	ScopeOrdersWrite  = "orders:write"
+	ScopeReportsRead  = "reports:read"
+)
+
+var (
+	// the messages are the RFC 6749 error codes, clients switch on them
+	ErrInvalidRequest       = errors.New(fiber.StatusBadRequest, "invalid_request")
+	ErrInvalidClient        = errors.New(fiber.StatusUnauthorized, "invalid_client")
+	ErrInvalidScope         = errors.New(fiber.StatusBadRequest, "invalid_scope")
+	ErrUnsupportedGrantType = errors.New(fiber.StatusBadRequest, "unsupported_grant_type")
+
+	Scopes = []string{
+		ScopeProductsRead,
+		ScopeOrdersRead,
+		ScopeOrdersWrite,
+		ScopeReportsRead,
+	}
+)
+
+type (
+	Client struct {
+		ID         int64      `json:"id"`
+		ClientID   string     `json:"client_id"`
+		Name       string     `json:"name"`
+		SecretHash string     `json:"-"`
+		Scopes     []string   `json:"scopes"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		RevokedAt  *time.Time `json:"revoked_at"`
+		CreatedBy  *int64     `json:"created_by"`
+		CreatedAt  time.Time  `json:"created_at"`
+		UpdatedAt  time.Time  `json:"updated_at"`
+	}
+
+	ClientFilter struct {
+		ClientIDs []string
+		Active    bool
+	}
+
+	CreateClientRequest struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+
+	// CreateClientResponse is the only place the plain secret is ever returned, only its hash is stored
+	CreateClientResponse struct {
+		Client
+		ClientSecret string `json:"client_secret"`
+	}
+
+	TokenRequest struct {
+		GrantType    string `json:"grant_type" form:"grant_type"`
+		ClientID     string `json:"client_id" form:"client_id"`
+		ClientSecret string `json:"client_secret" form:"client_secret"`
+		Scope        string `json:"scope" form:"scope"`
+	}
+
+	TokenResponse struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+
+	// TokenErrorResponse follows RFC 6749 section 5.2 so off the shelf OAuth2 clients understand it
+	TokenErrorResponse struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+
+	CurrentClient struct {
+		ID       int64    `json:"id"`
+		ClientID string   `json:"client_id"`
+		Name     string   `json:"name"`
+		Scopes   []string `json:"scopes"`
+		TokenID  string   `json:"-"`
+	}
+)
@@ -0,0 +1,127 @@
+package presenter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	middlewareJWT "github.com/roysitumorang/laukpauk/middleware/jwt"
+	middlewareRBAC "github.com/roysitumorang/laukpauk/middleware/rbac"
+	middlewareScope "github.com/roysitumorang/laukpauk/middleware/scope"
+	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
+	"github.com/roysitumorang/laukpauk/modules/oauth/model"
+	"github.com/roysitumorang/laukpauk/modules/oauth/sanitizer"
+	oauthUseCase "github.com/roysitumorang/laukpauk/modules/oauth/usecase"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	"go.uber.org/zap"
+)
+
+type (
+	oauthHTTPHandler struct {
+		authUseCase  authUseCase.AuthUseCase
+		oauthUseCase oauthUseCase.OAuthUseCase
+	}
+)
+
+func NewOAuthHTTPHandler(
+	authUseCase authUseCase.AuthUseCase,
+	oauthUseCase oauthUseCase.OAuthUseCase,
+) *oauthHTTPHandler {
+	return &oauthHTTPHandler{
+		authUseCase:  authUseCase,
+		oauthUseCase: oauthUseCase,
+	}
+}
+
+func (q *oauthHTTPHandler) Mount(r fiber.Router) {
+	r.Post("/token", q.Token)
+	r.Get("/me", middlewareJWT.NewJWT(q.authUseCase), middlewareScope.NewScope(q.oauthUseCase), q.Me)
+	r.Group("/clients", middlewareJWT.NewJWT(q.authUseCase), middlewareRBAC.NewRBAC(roleModel.RoleSuperAdmin)).
+		Get("", q.FindClients).
+		Post("", q.CreateClient).
+		Delete("/:client_id", q.RevokeClient)
+}
+
+// Token answers in the plain RFC 6749 format instead of our response envelope,
+// partners use stock OAuth2 client libraries which expect exactly that.
+func (q *oauthHTTPHandler) Token(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "OAuthPresenter-Token"
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+	request, statusCode, err := sanitizer.Token(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrToken")
+		return writeTokenError(c, statusCode, err)
+	}
+	response, err := q.oauthUseCase.IssueToken(ctx, request)
+	switch {
+	case errors.Is(err, model.ErrInvalidClient):
+		return writeTokenError(c, fiber.StatusUnauthorized, err)
+	case errors.Is(err, model.ErrInvalidScope),
+		errors.Is(err, model.ErrUnsupportedGrantType):
+		return writeTokenError(c, fiber.StatusBadRequest, err)
+	case err != nil:
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrIssueToken")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.TokenErrorResponse{Error: "server_error"})
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (q *oauthHTTPHandler) FindClients(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "OAuthPresenter-FindClients"
+	response, err := q.oauthUseCase.FindClients(ctx, model.ClientFilter{})
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindClients")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *oauthHTTPHandler) CreateClient(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "OAuthPresenter-CreateClient"
+	request, statusCode, err := sanitizer.CreateClient(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateClient")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.oauthUseCase.CreateClient(ctx, currentUser.ID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateClient")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusCreated, "", response).WriteResponse(c)
+}
+
+func (q *oauthHTTPHandler) RevokeClient(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "OAuthPresenter-RevokeClient"
+	if err := q.oauthUseCase.RevokeClient(ctx, c.Params("client_id")); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeClient")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func writeTokenError(c *fiber.Ctx, statusCode int, err error) error {
+	if statusCode == fiber.StatusUnauthorized {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	return c.Status(statusCode).JSON(model.TokenErrorResponse{Error: err.Error()})
+}
+
+// Me lets partner services check their token, it answers with the client and the scopes the token grants
+func (q *oauthHTTPHandler) Me(c *fiber.Ctx) error {
+	currentClient, ok := middlewareScope.GetCurrentClient(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", currentClient).WriteResponse(c)
+}
@@ -0,0 +1,181 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/oauth/model"
+	"go.uber.org/zap"
+)
+
+type (
+	oauthQuery struct {
+		dbRead, dbWrite *pgxpool.Pool
+	}
+)
+
+func NewOAuthQuery(
+	dbRead,
+	dbWrite *pgxpool.Pool,
+) OAuthQuery {
+	return &oauthQuery{
+		dbRead:  dbRead,
+		dbWrite: dbWrite,
+	}
+}
+
+func (q *oauthQuery) FindClients(ctx context.Context, filter model.ClientFilter) (response []model.Client, err error) {
+	ctxt := "OAuthQuery-FindClients"
+	var (
+		params     []interface{}
+		conditions []string
+	)
+	if n := len(filter.ClientIDs); n > 0 {
+		placeholders := make([]string, n)
+		for i, clientID := range filter.ClientIDs {
+			params = append(params, clientID)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		conditions = append(conditions, fmt.Sprintf("client_id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if filter.Active {
+		conditions = append(conditions, "revoked_at IS NULL")
+	}
+	query := `SELECT
+			id
+			, client_id
+			, name
+			, secret_hash
+			, scopes
+			, last_used_at
+			, revoked_at
+			, created_by
+			, created_at
+			, updated_at
+		FROM oauth_clients`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY -id"
+	// revocations must take effect immediately, so clients are never read from the replica
+	rows, err := q.dbWrite.Query(ctx, query, params...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var client model.Client
+		if err = rows.Scan(
+			&client.ID,
+			&client.ClientID,
+			&client.Name,
+			&client.SecretHash,
+			&client.Scopes,
+			&client.LastUsedAt,
+			&client.RevokedAt,
+			&client.CreatedBy,
+			&client.CreatedAt,
+			&client.UpdatedAt,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		response = append(response, client)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+func (q *oauthQuery) CreateClient(ctx context.Context, request model.CreateClientRequest, clientID, secretHash string, createdBy int64) (*model.Client, error) {
+	ctxt := "OAuthQuery-CreateClient"
+	id, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return nil, err
+	}
+	now := time.Now().UTC()
+	response := model.Client{
+		ID:         id,
+		ClientID:   clientID,
+		Name:       request.Name,
+		SecretHash: secretHash,
+		Scopes:     request.Scopes,
+		CreatedBy:  &createdBy,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO oauth_clients (
+			id
+			, client_id
+			, name
+			, secret_hash
+			, scopes
+			, created_by
+			, created_at
+			, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`,
+		response.ID,
+		response.ClientID,
+		response.Name,
+		response.SecretHash,
+		response.Scopes,
+		createdBy,
+		now,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (q *oauthQuery) RevokeClient(ctx context.Context, clientID string) (response int64, err error) {
+	ctxt := "OAuthQuery-RevokeClient"
+	now := time.Now().UTC()
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE oauth_clients SET
+			revoked_at = $1
+			, updated_at = $1
+		WHERE client_id = $2
+		AND revoked_at IS NULL
+		RETURNING id`,
+		now,
+		clientID,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *oauthQuery) TouchClient(ctx context.Context, id int64) (err error) {
+	ctxt := "OAuthQuery-TouchClient"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE oauth_clients SET
+			last_used_at = $1
+		WHERE id = $2`,
+		time.Now().UTC(),
+		id,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
@@ -0,0 +1,16 @@
+package query
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/modules/oauth/model"
+)
+
+type (
+	OAuthQuery interface {
+		FindClients(ctx context.Context, filter model.ClientFilter) (response []model.Client, err error)
+		CreateClient(ctx context.Context, request model.CreateClientRequest, clientID, secretHash string, createdBy int64) (response *model.Client, err error)
+		RevokeClient(ctx context.Context, clientID string) (response int64, err error)
+		TouchClient(ctx context.Context, id int64) (err error)
+	}
+)
@@ -0,0 +1,107 @@
+package sanitizer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/oauth/model"
+	"go.uber.org/zap"
+)
+
+func CreateClient(ctx context.Context, c *fiber.Ctx) (request model.CreateClientRequest, statusCode int, err error) {
+	ctxt := "OAuthSanitizer-CreateClient"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.Name = strings.TrimSpace(request.Name); request.Name == "" {
+		err = errors.New("name is required")
+		return
+	}
+	if len(request.Scopes) == 0 {
+		err = errors.New("scopes is required")
+		return
+	}
+	scopes := make([]string, 0, len(request.Scopes))
+	for _, scope := range request.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !slices.Contains(model.Scopes, scope) {
+			err = fmt.Errorf("invalid scope %s, valid scopes are %s", scope, strings.Join(model.Scopes, ", "))
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	request.Scopes = scopes
+	statusCode = fiber.StatusOK
+	return
+}
+
+// Token accepts the client credentials either through HTTP Basic authentication or in the
+// form/JSON body as RFC 6749 section 2.3.1 allows, but not both at once.
+func Token(ctx context.Context, c *fiber.Ctx) (request model.TokenRequest, statusCode int, err error) {
+	ctxt := "OAuthSanitizer-Token"
+	statusCode = fiber.StatusBadRequest
+	if err = c.BodyParser(&request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		err = model.ErrInvalidRequest
+		return
+	}
+	if authorization := c.Get(fiber.HeaderAuthorization); authorization != "" {
+		clientID, clientSecret, ok := parseBasicAuth(authorization)
+		if !ok || request.ClientID != "" || request.ClientSecret != "" {
+			statusCode = fiber.StatusUnauthorized
+			err = model.ErrInvalidClient
+			return
+		}
+		request.ClientID, request.ClientSecret = clientID, clientSecret
+	}
+	if request.GrantType = strings.TrimSpace(request.GrantType); request.GrantType == "" {
+		err = model.ErrInvalidRequest
+		return
+	}
+	if request.ClientID = strings.TrimSpace(request.ClientID); request.ClientID == "" || request.ClientSecret == "" {
+		statusCode = fiber.StatusUnauthorized
+		err = model.ErrInvalidClient
+		return
+	}
+	request.Scope = strings.Join(strings.Fields(request.Scope), " ")
+	statusCode = fiber.StatusOK
+	return
+}
+
+func parseBasicAuth(authorization string) (clientID, clientSecret string, ok bool) {
+	encoded, found := strings.CutPrefix(authorization, "Basic ")
+	if !found {
+		return
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return
+	}
+	clientID, clientSecret, found = strings.Cut(helper.ByteSlice2String(decoded), ":")
+	if !found {
+		return
+	}
+	// both parts are form encoded before being joined, see RFC 6749 section 2.3.1
+	if clientID, err = url.QueryUnescape(clientID); err != nil {
+		return
+	}
+	if clientSecret, err = url.QueryUnescape(clientSecret); err != nil {
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/roysitumorang/laukpauk/config"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/keys"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	"github.com/roysitumorang/laukpauk/modules/oauth/model"
+	oauthQuery "github.com/roysitumorang/laukpauk/modules/oauth/query"
+	"go.uber.org/zap"
+)
+
+const (
+	clientIDLength     = 24
+	clientSecretLength = 48
+	lastUsedAtInterval = time.Minute
+)
+
+type (
+	oauthUseCaseImplementation struct {
+		oauthQuery oauthQuery.OAuthQuery
+	}
+)
+
+func NewOAuthUseCase(
+	oauthQuery oauthQuery.OAuthQuery,
+) OAuthUseCase {
+	return &oauthUseCaseImplementation{
+		oauthQuery: oauthQuery,
+	}
+}
+
+func (q *oauthUseCaseImplementation) FindClients(ctx context.Context, filter model.ClientFilter) (response []model.Client, err error) {
+	ctxt := "OAuthUseCase-FindClients"
+	if response, err = q.oauthQuery.FindClients(ctx, filter); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindClients")
+	}
+	return
+}
+
+func (q *oauthUseCaseImplementation) CreateClient(ctx context.Context, createdBy int64, request model.CreateClientRequest) (response *model.CreateClientResponse, err error) {
+	ctxt := "OAuthUseCase-CreateClient"
+	clientID := model.ClientIDPrefix + helper.GenerateRandomString(clientIDLength)
+	clientSecret := helper.GenerateRandomString(clientSecretLength)
+	client, err := q.oauthQuery.CreateClient(ctx, request, clientID, helper.HashToken(clientSecret), createdBy)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCreateClient")
+		return
+	}
+	response = &model.CreateClientResponse{
+		Client:       *client,
+		ClientSecret: clientSecret,
+	}
+	return
+}
+
+func (q *oauthUseCaseImplementation) RevokeClient(ctx context.Context, clientID string) (err error) {
+	ctxt := "OAuthUseCase-RevokeClient"
+	revokedID, err := q.oauthQuery.RevokeClient(ctx, clientID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeClient")
+		return
+	}
+	if revokedID == 0 {
+		err = errors.New("client not found")
+	}
+	return
+}
+
+// IssueToken implements the client credentials grant, the token carries no user and is only
+// good for routes guarded by middleware/scope. Omitting scope grants every scope of the client.
+func (q *oauthUseCaseImplementation) IssueToken(ctx context.Context, request model.TokenRequest) (response *model.TokenResponse, err error) {
+	ctxt := "OAuthUseCase-IssueToken"
+	if request.GrantType != model.GrantTypeClientCredentials {
+		err = model.ErrUnsupportedGrantType
+		return
+	}
+	client, err := q.FindActiveClient(ctx, request.ClientID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindActiveClient")
+		return
+	}
+	if client == nil || subtle.ConstantTimeCompare(
+		helper.String2ByteSlice(helper.HashToken(request.ClientSecret)),
+		helper.String2ByteSlice(client.SecretHash),
+	) != 1 {
+		err = model.ErrInvalidClient
+		return
+	}
+	scopes := client.Scopes
+	if request.Scope != "" {
+		scopes = strings.Fields(request.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(client.Scopes, scope) {
+				err = model.ErrInvalidScope
+				return
+			}
+		}
+	}
+	if client.LastUsedAt == nil || time.Since(*client.LastUsedAt) > lastUsedAtInterval {
+		if err = q.oauthQuery.TouchClient(ctx, client.ID); err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrTouchClient")
+			return
+		}
+	}
+	now := time.Now()
+	tokenTTL := config.GetOAuthTokenTTL()
+	scope := strings.Join(scopes, " ")
+	claims := authModel.TokenClaims{
+		ClientID: client.ClientID,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        helper.GenerateRandomString(32),
+			Subject:   client.ClientID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+	}
+	accessToken, err := keys.Sign(claims)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSign")
+		return
+	}
+	response = &model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   model.TokenType,
+		ExpiresIn:   int64(tokenTTL.Seconds()),
+		Scope:       scope,
+	}
+	return
+}
+
+// FindActiveClient returns nil when clientID is unknown or revoked.
+func (q *oauthUseCaseImplementation) FindActiveClient(ctx context.Context, clientID string) (response *model.Client, err error) {
+	ctxt := "OAuthUseCase-FindActiveClient"
+	if clientID == "" {
+		return
+	}
+	clients, err := q.oauthQuery.FindClients(
+		ctx,
+		model.ClientFilter{
+			ClientIDs: []string{clientID},
+			Active:    true,
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindClients")
+		return
+	}
+	if len(clients) > 0 {
+		response = &clients[0]
+	}
+	return
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/modules/oauth/model"
+)
+
+type (
+	OAuthUseCase interface {
+		FindClients(ctx context.Context, filter model.ClientFilter) (response []model.Client, err error)
+		CreateClient(ctx context.Context, createdBy int64, request model.CreateClientRequest) (response *model.CreateClientResponse, err error)
+		RevokeClient(ctx context.Context, clientID string) (err error)
+		IssueToken(ctx context.Context, request model.TokenRequest) (response *model.TokenResponse, err error)
+		FindActiveClient(ctx context.Context, clientID string) (response *model.Client, err error)
+	}
+)
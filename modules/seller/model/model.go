@@ -0,0 +1,101 @@
+package model
+
+import (
+	"time"
+
+	regionModel "github.com/roysitumorang/laukpauk/modules/region/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+)
+
+const (
+	MaxMerchantNoteLength = 500
+
+	// MaxDeliveryDistance caps delivery_max_distance in kilometers, it also sizes the nearby search bounding box
+	MaxDeliveryDistance = 50.0
+
+	DefaultNearbyLimit = 20
+	MaxNearbyLimit     = 100
+)
+
+type (
+	StoreSettings struct {
+		BusinessDays         userModel.BusinessDays `json:"business_days"`
+		BusinessOpeningHour  *int                   `json:"business_opening_hour"`
+		BusinessClosingHour  *int                   `json:"business_closing_hour"`
+		DeliveryHours        []int                  `json:"delivery_hours"`
+		MinimumPurchase      int                    `json:"minimum_purchase"`
+		MerchantNote         *string                `json:"merchant_note"`
+		DeliveryMaxDistance  int                    `json:"delivery_max_distance"`
+		DeliveryFreeDistance int                    `json:"delivery_free_distance"`
+		DeliveryRate         int                    `json:"delivery_rate"`
+		UpdatedAt            *time.Time             `json:"updated_at,omitempty"`
+		Schedule             *Schedule              `json:"schedule,omitempty"`
+	}
+
+	Seller struct {
+		ID              int64    `json:"id"`
+		StoreName       string   `json:"store_name"`
+		MerchantNote    *string  `json:"merchant_note"`
+		MinimumPurchase int      `json:"minimum_purchase"`
+		DeliveryRate    int      `json:"delivery_rate"`
+		Distance        *float64 `json:"distance,omitempty"`
+		Schedule
+		DeliveryFreeDistance int                     `json:"-"`
+		DeliveryMaxDistance  int                     `json:"-"`
+		Latitude             *float64                `json:"-"`
+		Longitude            *float64                `json:"-"`
+		BusinessDays         *userModel.BusinessDays `json:"-"`
+		BusinessOpeningHour  *int                    `json:"-"`
+		BusinessClosingHour  *int                    `json:"-"`
+		DeliveryHours        []int                   `json:"-"`
+	}
+
+	// CoverageArea is a village the seller delivers to, DeliveryFee overrides the distance based fee when set
+	CoverageArea struct {
+		Village     regionModel.Region `json:"village"`
+		Subdistrict regionModel.Region `json:"subdistrict"`
+		City        regionModel.Region `json:"city"`
+		DeliveryFee *int               `json:"delivery_fee"`
+	}
+
+	// CoverageRequest selects villages one by one or all villages of a subdistrict or city at once
+	CoverageRequest struct {
+		VillageIDs     []int64 `json:"village_ids"`
+		SubdistrictIDs []int64 `json:"subdistrict_ids"`
+		CityIDs        []int64 `json:"city_ids"`
+		DeliveryFee    *int    `json:"delivery_fee"`
+	}
+
+	CoverageResponse struct {
+		Villages int64 `json:"villages"`
+	}
+
+	// NearbyRequest defaults to the buyer's own location when Latitude and Longitude are omitted
+	NearbyRequest struct {
+		Latitude  float64
+		Longitude float64
+		Located   bool
+		Limit     int
+	}
+
+	DeliveryQuoteRequest struct {
+		SellerID  int64
+		VillageID int64
+		Latitude  float64
+		Longitude float64
+		Located   bool
+	}
+
+	// DeliveryQuote leaves Distance out when a coverage area fee applied and no location was known
+	DeliveryQuote struct {
+		Deliverable bool     `json:"deliverable"`
+		Distance    *float64 `json:"distance"`
+		Fee         int      `json:"fee"`
+		Reason      string   `json:"reason,omitempty"`
+	}
+
+	SellerFilter struct {
+		SellerIDs []int64
+		VillageID int64
+	}
+)
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"math"
+)
+
+// DeliveryFee prices a delivery over distance kilometers, it is free up to delivery_free_distance
+// and every started kilometer beyond costs delivery_rate. ok is false past delivery_max_distance,
+// and for distances that can't be priced at all.
+func (q Seller) DeliveryFee(distance float64) (fee int, ok bool) {
+	if math.IsNaN(distance) || math.IsInf(distance, 0) || distance < 0 || distance > float64(q.DeliveryMaxDistance) {
+		return 0, false
+	}
+	if distance <= float64(q.DeliveryFreeDistance) {
+		return 0, true
+	}
+	return int(math.Ceil(distance-float64(q.DeliveryFreeDistance))) * q.DeliveryRate, true
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeliveryFee(t *testing.T) {
+	seller := Seller{
+		DeliveryRate:         2000,
+		DeliveryFreeDistance: 3,
+		DeliveryMaxDistance:  10,
+	}
+	for _, tc := range []struct {
+		name     string
+		distance float64
+		fee      int
+		ok       bool
+	}{
+		{"zero distance", 0, 0, true},
+		{"within free distance", 1.5, 0, true},
+		{"exactly free distance", 3, 0, true},
+		{"just past free distance", 3.01, 2000, true},
+		{"started kilometers round up", 5.2, 6000, true},
+		{"exactly max distance", 10, 14000, true},
+		{"past max distance", 10.01, 0, false},
+		{"negative distance", -1, 0, false},
+		{"NaN distance", math.NaN(), 0, false},
+		{"infinite distance", math.Inf(1), 0, false},
+		{"negative infinite distance", math.Inf(-1), 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fee, ok := seller.DeliveryFee(tc.distance)
+			if fee != tc.fee || ok != tc.ok {
+				t.Errorf("DeliveryFee(%v) = %d, %v, want %d, %v", tc.distance, fee, ok, tc.fee, tc.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"time"
+
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+)
+
+const (
+	storeTimezone = "Asia/Jakarta"
+	// nextOpeningLookahead covers a full week, a store closed every day never opens
+	nextOpeningLookahead = 7
+)
+
+var (
+	// StoreLocation is the timezone business and delivery hours are expressed in, hosts
+	// without tzdata get the equivalent fixed offset as Indonesia has no daylight saving time.
+	StoreLocation = loadStoreLocation(storeTimezone)
+)
+
+type (
+	BusinessHours struct {
+		Days          *userModel.BusinessDays
+		OpeningHour   *int
+		ClosingHour   *int
+		DeliveryHours []int
+	}
+
+	Schedule struct {
+		OpenNow       bool           `json:"open_now"`
+		NextOpenAt    *time.Time     `json:"next_open_at"`
+		DeliverySlots []DeliverySlot `json:"delivery_slots"`
+	}
+
+	// DeliverySlot is an hour long window starting at one of the seller's delivery hours
+	DeliverySlot struct {
+		StartAt time.Time `json:"start_at"`
+		EndAt   time.Time `json:"end_at"`
+	}
+)
+
+func loadStoreLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return location
+}
+
+func (q Seller) BusinessHours() BusinessHours {
+	return BusinessHours{
+		Days:          q.BusinessDays,
+		OpeningHour:   q.BusinessOpeningHour,
+		ClosingHour:   q.BusinessClosingHour,
+		DeliveryHours: q.DeliveryHours,
+	}
+}
+
+func (q StoreSettings) BusinessHours() BusinessHours {
+	return BusinessHours{
+		Days:          &q.BusinessDays,
+		OpeningHour:   q.BusinessOpeningHour,
+		ClosingHour:   q.BusinessClosingHour,
+		DeliveryHours: q.DeliveryHours,
+	}
+}
+
+// ScheduleAt tells whether the store is open at now, when it opens next if it isn't,
+// and which delivery slots haven't started yet today and tomorrow.
+func (q BusinessHours) ScheduleAt(now time.Time) Schedule {
+	response := Schedule{
+		DeliverySlots: []DeliverySlot{},
+	}
+	if q.Days == nil || q.OpeningHour == nil || q.ClosingHour == nil {
+		return response
+	}
+	now = now.In(StoreLocation)
+	response.OpenNow = q.IsOpenAt(now)
+	if !response.OpenNow {
+		for i := 0; i <= nextOpeningLookahead; i++ {
+			openAt := q.at(now, i, *q.OpeningHour)
+			if q.Days.IsOpenOn(openAt.Weekday()) && openAt.After(now) {
+				response.NextOpenAt = &openAt
+				break
+			}
+		}
+	}
+	for i := 0; i <= 1; i++ {
+		for _, deliveryHour := range q.DeliveryHours {
+			startAt := q.at(now, i, deliveryHour)
+			if !startAt.After(now) || !q.Days.IsOpenOn(q.businessDay(startAt)) {
+				continue
+			}
+			response.DeliverySlots = append(
+				response.DeliverySlots,
+				DeliverySlot{
+					StartAt: startAt,
+					EndAt:   startAt.Add(time.Hour),
+				},
+			)
+		}
+	}
+	return response
+}
+
+// IsOpenAt tells whether the store is open at t, stores without business hours are never open.
+func (q BusinessHours) IsOpenAt(t time.Time) bool {
+	if q.Days == nil || q.OpeningHour == nil || q.ClosingHour == nil {
+		return false
+	}
+	t = t.In(StoreLocation)
+	if !q.overnight() {
+		return q.Days.IsOpenOn(t.Weekday()) &&
+			t.Hour() >= *q.OpeningHour &&
+			t.Hour() < *q.ClosingHour
+	}
+	return (t.Hour() >= *q.OpeningHour || t.Hour() < *q.ClosingHour) &&
+		q.Days.IsOpenOn(q.businessDay(t))
+}
+
+// overnight tells whether the store closes after midnight, closing at or before the
+// opening hour means closing on the next day, the same hour for both means never closing
+func (q BusinessHours) overnight() bool {
+	return *q.ClosingHour <= *q.OpeningHour
+}
+
+// businessDay returns the weekday whose business hours t falls in, past midnight
+// an overnight store is still on the previous day's hours
+func (q BusinessHours) businessDay(t time.Time) time.Weekday {
+	if q.overnight() && t.Hour() < *q.ClosingHour {
+		return (t.Weekday() + 6) % 7
+	}
+	return t.Weekday()
+}
+
+// at returns hour o'clock, days after the date of now, in the store's timezone
+func (q BusinessHours) at(now time.Time, days, hour int) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day+days, hour, 0, 0, 0, StoreLocation)
+}
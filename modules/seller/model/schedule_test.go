@@ -0,0 +1,152 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+)
+
+func hour(h int) *int {
+	return &h
+}
+
+// wib returns a wall clock time in the store's timezone, 19 October 2026 is a Monday
+func wib(day, hour, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, StoreLocation)
+}
+
+func TestLoadStoreLocation(t *testing.T) {
+	at := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	fallback := loadStoreLocation("Nowhere/Invalid")
+	if _, offset := at.In(fallback).Zone(); offset != 7*60*60 {
+		t.Errorf("fallback offset = %d, want %d", offset, 7*60*60)
+	}
+	if _, err := time.LoadLocation(storeTimezone); err != nil {
+		t.Skipf("no tzdata for %s: %v", storeTimezone, err)
+	}
+	if _, offset := at.In(loadStoreLocation(storeTimezone)).Zone(); offset != 7*60*60 {
+		t.Errorf("%s offset = %d, want %d", storeTimezone, offset, 7*60*60)
+	}
+}
+
+func TestIsOpenAt(t *testing.T) {
+	weekdays := &userModel.BusinessDays{Monday: true, Tuesday: true, Wednesday: true, Thursday: true, Friday: true}
+	for _, tc := range []struct {
+		name  string
+		hours BusinessHours
+		at    time.Time
+		open  bool
+	}{
+		{"no business hours", BusinessHours{}, wib(19, 10, 0), false},
+		{"no business days", BusinessHours{OpeningHour: hour(8), ClosingHour: hour(17)}, wib(19, 10, 0), false},
+		{"before opening", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(17)}, wib(19, 7, 59), false},
+		{"at opening", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(17)}, wib(19, 8, 0), true},
+		{"last minute before closing", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(17)}, wib(19, 16, 59), true},
+		{"at closing", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(17)}, wib(19, 17, 0), false},
+		{"closed day", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(17)}, wib(18, 10, 0), false},
+		{"overnight before midnight", BusinessHours{Days: weekdays, OpeningHour: hour(18), ClosingHour: hour(2)}, wib(19, 23, 59), true},
+		{"overnight past midnight", BusinessHours{Days: weekdays, OpeningHour: hour(18), ClosingHour: hour(2)}, wib(20, 1, 59), true},
+		{"overnight at closing", BusinessHours{Days: weekdays, OpeningHour: hour(18), ClosingHour: hour(2)}, wib(20, 2, 0), false},
+		{"overnight before opening", BusinessHours{Days: weekdays, OpeningHour: hour(18), ClosingHour: hour(2)}, wib(19, 17, 59), false},
+		{"overnight into a closed day", BusinessHours{Days: weekdays, OpeningHour: hour(18), ClosingHour: hour(2)}, wib(24, 1, 0), true},
+		{"overnight out of a closed day", BusinessHours{Days: weekdays, OpeningHour: hour(18), ClosingHour: hour(2)}, wib(19, 1, 0), false},
+		{"around the clock", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(8)}, wib(20, 7, 59), true},
+		{"around the clock after the last day", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(8)}, wib(25, 8, 0), false},
+		{"converted to store timezone", BusinessHours{Days: weekdays, OpeningHour: hour(8), ClosingHour: hour(17)}, time.Date(2026, time.October, 19, 2, 0, 0, 0, time.UTC), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if open := tc.hours.IsOpenAt(tc.at); open != tc.open {
+				t.Errorf("IsOpenAt(%v) = %v, want %v", tc.at, open, tc.open)
+			}
+		})
+	}
+}
+
+func TestScheduleAt(t *testing.T) {
+	mondayTuesday := &userModel.BusinessDays{Monday: true, Tuesday: true}
+	mondayOnly := &userModel.BusinessDays{Monday: true}
+	for _, tc := range []struct {
+		name       string
+		hours      BusinessHours
+		now        time.Time
+		openNow    bool
+		nextOpenAt *time.Time
+		slots      []time.Time
+	}{
+		{
+			name:  "no business hours",
+			hours: BusinessHours{DeliveryHours: []int{9}},
+			now:   wib(19, 10, 0),
+		},
+		{
+			name:    "open with slots left today and tomorrow",
+			hours:   BusinessHours{Days: mondayTuesday, OpeningHour: hour(8), ClosingHour: hour(17), DeliveryHours: []int{9, 15}},
+			now:     wib(19, 10, 0),
+			openNow: true,
+			slots:   []time.Time{wib(19, 15, 0), wib(20, 9, 0), wib(20, 15, 0)},
+		},
+		{
+			name:       "before opening today",
+			hours:      BusinessHours{Days: mondayTuesday, OpeningHour: hour(8), ClosingHour: hour(17), DeliveryHours: []int{9}},
+			now:        wib(19, 6, 0),
+			nextOpenAt: timePointer(wib(19, 8, 0)),
+			slots:      []time.Time{wib(19, 9, 0), wib(20, 9, 0)},
+		},
+		{
+			name:       "rolls over midnight to tomorrow",
+			hours:      BusinessHours{Days: mondayTuesday, OpeningHour: hour(8), ClosingHour: hour(17), DeliveryHours: []int{9, 15}},
+			now:        wib(19, 23, 30),
+			nextOpenAt: timePointer(wib(20, 8, 0)),
+			slots:      []time.Time{wib(20, 9, 0), wib(20, 15, 0)},
+		},
+		{
+			name:    "overnight slots belong to the previous day",
+			hours:   BusinessHours{Days: mondayTuesday, OpeningHour: hour(18), ClosingHour: hour(2), DeliveryHours: []int{1, 23}},
+			now:     wib(19, 23, 30),
+			openNow: true,
+			slots:   []time.Time{wib(20, 1, 0), wib(20, 23, 0)},
+		},
+		{
+			name:       "overnight past closing",
+			hours:      BusinessHours{Days: mondayOnly, OpeningHour: hour(18), ClosingHour: hour(2), DeliveryHours: []int{1, 23}},
+			now:        wib(20, 3, 0),
+			nextOpenAt: timePointer(wib(26, 18, 0)),
+		},
+		{
+			name:       "next opening a week out",
+			hours:      BusinessHours{Days: mondayOnly, OpeningHour: hour(8), ClosingHour: hour(17), DeliveryHours: []int{9}},
+			now:        wib(19, 18, 0),
+			nextOpenAt: timePointer(wib(26, 8, 0)),
+		},
+		{
+			name:  "never open",
+			hours: BusinessHours{Days: &userModel.BusinessDays{}, OpeningHour: hour(8), ClosingHour: hour(17), DeliveryHours: []int{9}},
+			now:   wib(19, 10, 0),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := tc.hours.ScheduleAt(tc.now)
+			if schedule.OpenNow != tc.openNow {
+				t.Errorf("OpenNow = %v, want %v", schedule.OpenNow, tc.openNow)
+			}
+			switch {
+			case schedule.NextOpenAt == nil && tc.nextOpenAt == nil:
+			case schedule.NextOpenAt == nil || tc.nextOpenAt == nil || !schedule.NextOpenAt.Equal(*tc.nextOpenAt):
+				t.Errorf("NextOpenAt = %v, want %v", schedule.NextOpenAt, tc.nextOpenAt)
+			}
+			if len(schedule.DeliverySlots) != len(tc.slots) {
+				t.Fatalf("got %d delivery slots, want %d: %v", len(schedule.DeliverySlots), len(tc.slots), schedule.DeliverySlots)
+			}
+			for i, slot := range schedule.DeliverySlots {
+				if !slot.StartAt.Equal(tc.slots[i]) || !slot.EndAt.Equal(tc.slots[i].Add(time.Hour)) {
+					t.Errorf("slot %d = %v - %v, want it to start at %v", i, slot.StartAt, slot.EndAt, tc.slots[i])
+				}
+			}
+		})
+	}
+}
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
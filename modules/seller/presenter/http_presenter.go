@@ -0,0 +1,246 @@
+package presenter
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	middlewareJWT "github.com/roysitumorang/laukpauk/middleware/jwt"
+	middlewareRBAC "github.com/roysitumorang/laukpauk/middleware/rbac"
+	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	"github.com/roysitumorang/laukpauk/modules/seller/sanitizer"
+	sellerUseCase "github.com/roysitumorang/laukpauk/modules/seller/usecase"
+	"go.uber.org/zap"
+)
+
+type (
+	sellerHTTPHandler struct {
+		authUseCase   authUseCase.AuthUseCase
+		sellerUseCase sellerUseCase.SellerUseCase
+	}
+)
+
+func NewSellerHTTPHandler(
+	authUseCase authUseCase.AuthUseCase,
+	sellerUseCase sellerUseCase.SellerUseCase,
+) *sellerHTTPHandler {
+	return &sellerHTTPHandler{
+		authUseCase:   authUseCase,
+		sellerUseCase: sellerUseCase,
+	}
+}
+
+func (q *sellerHTTPHandler) Mount(r fiber.Router) {
+	bearerVerifier := middlewareJWT.NewJWT(q.authUseCase)
+	buyerRBAC := middlewareRBAC.NewRBAC(roleModel.RoleBuyer)
+	r.Get("", bearerVerifier, buyerRBAC, q.FindSellers).
+		Get("/nearby", bearerVerifier, buyerRBAC, q.FindNearbySellers).
+		Get("/:seller_id<int>", bearerVerifier, buyerRBAC, q.FindSeller).
+		Get("/:seller_id<int>/delivery-quote", bearerVerifier, buyerRBAC, q.QuoteDelivery)
+	r.Group("/store", bearerVerifier, middlewareRBAC.NewRBAC(roleModel.RoleSeller)).
+		Get("", q.FindStoreSettings).
+		Put("", q.UpdateStoreSettings).
+		Get("/coverage", q.FindCoverageAreas).
+		Post("/coverage", q.AddCoverageAreas).
+		Put("/coverage", q.SetCoverageDeliveryFee).
+		Delete("/coverage", q.RemoveCoverageAreas)
+	// admins manage any seller's coverage, the int constraint keeps /store from matching :seller_id
+	adminRBAC := middlewareRBAC.NewRBAC(roleModel.RoleSuperAdmin, roleModel.RoleAdmin)
+	r.Get("/:seller_id<int>/coverage", bearerVerifier, adminRBAC, q.FindCoverageAreas).
+		Post("/:seller_id<int>/coverage", bearerVerifier, adminRBAC, q.AddCoverageAreas).
+		Put("/:seller_id<int>/coverage", bearerVerifier, adminRBAC, q.SetCoverageDeliveryFee).
+		Delete("/:seller_id<int>/coverage", bearerVerifier, adminRBAC, q.RemoveCoverageAreas)
+}
+
+func (q *sellerHTTPHandler) FindSellers(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-FindSellers"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	villageID, _ := strconv.ParseInt(c.Query("village_id"), 10, 64)
+	response, err := q.sellerUseCase.FindBuyerSellers(ctx, currentUser.ID, villageID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindBuyerSellers")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) FindSeller(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-FindSeller"
+	sellerID, _ := strconv.ParseInt(c.Params("seller_id"), 10, 64)
+	response, err := q.sellerUseCase.FindSeller(ctx, sellerID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSeller")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) FindNearbySellers(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-FindNearbySellers"
+	request, statusCode, err := sanitizer.Nearby(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrNearby")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.FindNearbySellers(ctx, currentUser.ID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindNearbySellers")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) QuoteDelivery(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-QuoteDelivery"
+	request, statusCode, err := sanitizer.DeliveryQuote(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrDeliveryQuote")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.QuoteDelivery(ctx, currentUser.ID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrQuoteDelivery")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) FindStoreSettings(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-FindStoreSettings"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.FindStoreSettings(ctx, currentUser.ID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindStoreSettings")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) UpdateStoreSettings(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-UpdateStoreSettings"
+	request, statusCode, err := sanitizer.StoreSettings(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrStoreSettings")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.UpdateStoreSettings(ctx, *currentUser, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUpdateStoreSettings")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) FindCoverageAreas(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-FindCoverageAreas"
+	sellerID, ok := getSellerID(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.FindCoverageAreas(ctx, sellerID)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindCoverageAreas")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) AddCoverageAreas(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-AddCoverageAreas"
+	request, statusCode, err := sanitizer.Coverage(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCoverage")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	sellerID, ok := getSellerID(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.AddCoverageAreas(ctx, sellerID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrAddCoverageAreas")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) SetCoverageDeliveryFee(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-SetCoverageDeliveryFee"
+	request, statusCode, err := sanitizer.Coverage(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCoverage")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	sellerID, ok := getSellerID(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.SetCoverageDeliveryFee(ctx, sellerID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSetCoverageDeliveryFee")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+func (q *sellerHTTPHandler) RemoveCoverageAreas(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "SellerPresenter-RemoveCoverageAreas"
+	request, statusCode, err := sanitizer.Coverage(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCoverage")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	sellerID, ok := getSellerID(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	response, err := q.sellerUseCase.RemoveCoverageAreas(ctx, sellerID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRemoveCoverageAreas")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
+
+// getSellerID returns the :seller_id of admin routes, or the current seller on /store routes
+func getSellerID(c *fiber.Ctx) (int64, bool) {
+	if param := c.Params("seller_id"); param != "" {
+		sellerID, _ := strconv.ParseInt(param, 10, 64)
+		return sellerID, true
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return 0, false
+	}
+	return currentUser.ID, true
+}
@@ -0,0 +1,20 @@
+package query
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/modules/seller/model"
+)
+
+type (
+	SellerQuery interface {
+		FindSellers(ctx context.Context, filter model.SellerFilter) (response []model.Seller, err error)
+		FindNearbySellers(ctx context.Context, request model.NearbyRequest) (response []model.Seller, err error)
+		UpdateStoreSettings(ctx context.Context, userID int64, request model.StoreSettings, updatedBy int64) (response int64, err error)
+		FindCoverageAreas(ctx context.Context, sellerID int64) (response []model.CoverageArea, err error)
+		FindVillages(ctx context.Context, request model.CoverageRequest) (response []model.CoverageArea, err error)
+		AddCoverageAreas(ctx context.Context, sellerID int64, villageIDs []int64, deliveryFee *int) (response int64, err error)
+		SetCoverageDeliveryFee(ctx context.Context, sellerID int64, villageIDs []int64, deliveryFee *int) (response int64, err error)
+		RemoveCoverageAreas(ctx context.Context, sellerID int64, villageIDs []int64) (response int64, err error)
+	}
+)
@@ -0,0 +1,446 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/roysitumorang/laukpauk/helper"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	"github.com/roysitumorang/laukpauk/modules/seller/model"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+const (
+	sellerColumns = `u.id
+		, COALESCE(NULLIF(u.company, ''), u.name) AS store_name
+		, u.merchant_note
+		, u.minimum_purchase
+		, u.delivery_rate
+		, u.delivery_free_distance
+		, u.delivery_max_distance
+		, u.latitude
+		, u.longitude
+		, u.business_days
+		, u.business_opening_hour
+		, u.business_closing_hour
+		, u.delivery_hours`
+)
+
+type (
+	sellerQuery struct {
+		dbRead, dbWrite *pgxpool.Pool
+	}
+)
+
+func NewSellerQuery(
+	dbRead,
+	dbWrite *pgxpool.Pool,
+) SellerQuery {
+	return &sellerQuery{
+		dbRead:  dbRead,
+		dbWrite: dbWrite,
+	}
+}
+
+// FindSellers only returns active sellers, ordered by store name
+func (q *sellerQuery) FindSellers(ctx context.Context, filter model.SellerFilter) (response []model.Seller, err error) {
+	ctxt := "SellerQuery-FindSellers"
+	params := []interface{}{
+		roleModel.RoleSeller,
+		userModel.StatusActive,
+	}
+	conditions := []string{
+		"u.role_id = $1",
+		"u.status = $2",
+	}
+	if n := len(filter.SellerIDs); n > 0 {
+		placeholders := make([]string, n)
+		for i, sellerID := range filter.SellerIDs {
+			params = append(params, sellerID)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		conditions = append(conditions, fmt.Sprintf("u.id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if filter.VillageID != 0 {
+		params = append(params, filter.VillageID)
+		conditions = append(
+			conditions,
+			fmt.Sprintf(
+				`EXISTS(
+					SELECT 1
+					FROM coverage_area e
+					WHERE e.user_id = u.id
+					AND e.village_id = $%d
+				)`,
+				len(params),
+			),
+		)
+	}
+	return q.querySellers(
+		ctx,
+		ctxt,
+		fmt.Sprintf(
+			`SELECT %s
+			FROM users u
+			WHERE %s
+			ORDER BY store_name`,
+			sellerColumns,
+			strings.Join(conditions, " AND "),
+		),
+		false,
+		params...,
+	)
+}
+
+// FindNearbySellers returns the sellers whose delivery_max_distance reaches the given point, nearest first.
+// The bounding box lets users_latitude_longitude_idx narrow candidates before the haversine distance is computed.
+func (q *sellerQuery) FindNearbySellers(ctx context.Context, request model.NearbyRequest) (response []model.Seller, err error) {
+	ctxt := "SellerQuery-FindNearbySellers"
+	latitudeDelta := model.MaxDeliveryDistance / helper.KilometersPerDegree
+	longitudeDelta := model.MaxDeliveryDistance / (helper.KilometersPerDegree * math.Max(math.Cos(request.Latitude*math.Pi/180), 0.01))
+	return q.querySellers(
+		ctx,
+		ctxt,
+		fmt.Sprintf(
+			`SELECT * FROM (
+				SELECT %s
+					, %f * 2 * asin(LEAST(1, sqrt(
+						power(sin(radians(u.latitude - $3) / 2), 2)
+						+ cos(radians($3)) * cos(radians(u.latitude)) * power(sin(radians(u.longitude - $4) / 2), 2)
+					))) AS distance
+				FROM users u
+				WHERE u.role_id = $1
+				AND u.status = $2
+				AND u.latitude BETWEEN $5 AND $6
+				AND u.longitude BETWEEN $7 AND $8
+			) sellers
+			WHERE distance <= delivery_max_distance
+			ORDER BY distance
+			LIMIT $9`,
+			sellerColumns,
+			helper.EarthRadius,
+		),
+		true,
+		roleModel.RoleSeller,
+		userModel.StatusActive,
+		request.Latitude,
+		request.Longitude,
+		request.Latitude-latitudeDelta,
+		request.Latitude+latitudeDelta,
+		request.Longitude-longitudeDelta,
+		request.Longitude+longitudeDelta,
+		request.Limit,
+	)
+}
+
+func (q *sellerQuery) querySellers(ctx context.Context, ctxt, query string, withDistance bool, params ...interface{}) (response []model.Seller, err error) {
+	rows, err := q.dbRead.Query(ctx, query, params...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			seller           model.Seller
+			businessDaysByte []byte
+		)
+		dest := []interface{}{
+			&seller.ID,
+			&seller.StoreName,
+			&seller.MerchantNote,
+			&seller.MinimumPurchase,
+			&seller.DeliveryRate,
+			&seller.DeliveryFreeDistance,
+			&seller.DeliveryMaxDistance,
+			&seller.Latitude,
+			&seller.Longitude,
+			&businessDaysByte,
+			&seller.BusinessOpeningHour,
+			&seller.BusinessClosingHour,
+			&seller.DeliveryHours,
+		}
+		if withDistance {
+			dest = append(dest, &seller.Distance)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		if businessDaysByte != nil {
+			var businessDays userModel.BusinessDays
+			if err = json.Unmarshal(businessDaysByte, &businessDays); err != nil {
+				helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrUnmarshal")
+				return
+			}
+			seller.BusinessDays = &businessDays
+		}
+		response = append(response, seller)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+func (q *sellerQuery) UpdateStoreSettings(ctx context.Context, userID int64, request model.StoreSettings, updatedBy int64) (response int64, err error) {
+	ctxt := "SellerQuery-UpdateStoreSettings"
+	businessDaysByte, err := json.Marshal(request.BusinessDays)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrMarshal")
+		return
+	}
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE users SET
+			business_days = $1
+			, business_opening_hour = $2
+			, business_closing_hour = $3
+			, delivery_hours = $4
+			, minimum_purchase = $5
+			, merchant_note = $6
+			, delivery_max_distance = $7
+			, delivery_free_distance = $8
+			, delivery_rate = $9
+			, updated_by = $10
+			, updated_at = $11
+		WHERE id = $12
+		AND role_id = $13
+		AND status = $14
+		RETURNING id`,
+		helper.ByteSlice2String(businessDaysByte),
+		request.BusinessOpeningHour,
+		request.BusinessClosingHour,
+		request.DeliveryHours,
+		request.MinimumPurchase,
+		request.MerchantNote,
+		request.DeliveryMaxDistance,
+		request.DeliveryFreeDistance,
+		request.DeliveryRate,
+		updatedBy,
+		time.Now().UTC(),
+		userID,
+		roleModel.RoleSeller,
+		userModel.StatusActive,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *sellerQuery) FindCoverageAreas(ctx context.Context, sellerID int64) (response []model.CoverageArea, err error) {
+	ctxt := "SellerQuery-FindCoverageAreas"
+	rows, err := q.dbRead.Query(
+		ctx,
+		`SELECT
+			v.id
+			, v.name
+			, s.id
+			, s.name
+			, c.id
+			, c.type || ' ' || c.name
+			, e.delivery_fee
+		FROM coverage_area e
+		JOIN villages v ON e.village_id = v.id
+		JOIN subdistricts s ON v.subdistrict_id = s.id
+		JOIN cities c ON s.city_id = c.id
+		WHERE e.user_id = $1
+		ORDER BY c.name, s.name, v.name`,
+		sellerID,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var coverageArea model.CoverageArea
+		if err = rows.Scan(
+			&coverageArea.Village.ID,
+			&coverageArea.Village.Name,
+			&coverageArea.Subdistrict.ID,
+			&coverageArea.Subdistrict.Name,
+			&coverageArea.City.ID,
+			&coverageArea.City.Name,
+			&coverageArea.DeliveryFee,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		response = append(response, coverageArea)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+// FindVillages expands a coverage request into the villages it selects, unknown region ids simply match nothing
+func (q *sellerQuery) FindVillages(ctx context.Context, request model.CoverageRequest) (response []model.CoverageArea, err error) {
+	ctxt := "SellerQuery-FindVillages"
+	var (
+		params     []interface{}
+		conditions []string
+	)
+	for _, item := range []struct {
+		column string
+		ids    []int64
+	}{
+		{"v.id", request.VillageIDs},
+		{"v.subdistrict_id", request.SubdistrictIDs},
+		{"s.city_id", request.CityIDs},
+	} {
+		if len(item.ids) == 0 {
+			continue
+		}
+		params = append(params, item.ids)
+		conditions = append(conditions, fmt.Sprintf("%s = ANY($%d)", item.column, len(params)))
+	}
+	if len(conditions) == 0 {
+		return
+	}
+	rows, err := q.dbRead.Query(
+		ctx,
+		fmt.Sprintf(
+			`SELECT
+				v.id
+				, v.name
+				, s.id
+				, s.name
+				, c.id
+				, c.type || ' ' || c.name
+			FROM villages v
+			JOIN subdistricts s ON v.subdistrict_id = s.id
+			JOIN cities c ON s.city_id = c.id
+			WHERE %s
+			ORDER BY v.id`,
+			strings.Join(conditions, " OR "),
+		),
+		params...,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrQuery")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var village model.CoverageArea
+		if err = rows.Scan(
+			&village.Village.ID,
+			&village.Village.Name,
+			&village.Subdistrict.ID,
+			&village.Subdistrict.Name,
+			&village.City.ID,
+			&village.City.Name,
+		); err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+			return
+		}
+		response = append(response, village)
+	}
+	if err = rows.Err(); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrErr")
+	}
+	return
+}
+
+// AddCoverageAreas upserts the villages in one statement whatever their number, a nil fee leaves
+// the fee of villages already covered as it is.
+func (q *sellerQuery) AddCoverageAreas(ctx context.Context, sellerID int64, villageIDs []int64, deliveryFee *int) (response int64, err error) {
+	ctxt := "SellerQuery-AddCoverageAreas"
+	if len(villageIDs) == 0 {
+		return
+	}
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`INSERT INTO coverage_area (
+			user_id
+			, village_id
+			, delivery_fee
+			, created_at
+			, updated_at
+		)
+		SELECT $1, village_id, $2, $3, $3
+		FROM unnest($4::bigint[]) AS village_id
+		ON CONFLICT (user_id, village_id) DO UPDATE SET
+			delivery_fee = COALESCE(EXCLUDED.delivery_fee, coverage_area.delivery_fee)
+			, updated_at = EXCLUDED.updated_at`,
+		sellerID,
+		deliveryFee,
+		time.Now().UTC(),
+		villageIDs,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected()
+	return
+}
+
+func (q *sellerQuery) SetCoverageDeliveryFee(ctx context.Context, sellerID int64, villageIDs []int64, deliveryFee *int) (response int64, err error) {
+	ctxt := "SellerQuery-SetCoverageDeliveryFee"
+	if len(villageIDs) == 0 {
+		return
+	}
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`UPDATE coverage_area SET
+			delivery_fee = $1
+			, updated_at = $2
+		WHERE user_id = $3
+		AND village_id = ANY($4)`,
+		deliveryFee,
+		time.Now().UTC(),
+		sellerID,
+		villageIDs,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected()
+	return
+}
+
+func (q *sellerQuery) RemoveCoverageAreas(ctx context.Context, sellerID int64, villageIDs []int64) (response int64, err error) {
+	ctxt := "SellerQuery-RemoveCoverageAreas"
+	if len(villageIDs) == 0 {
+		return
+	}
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`DELETE FROM coverage_area
+		WHERE user_id = $1
+		AND village_id = ANY($2)`,
+		sellerID,
+		villageIDs,
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected()
+	return
+}
@@ -0,0 +1,183 @@
+package sanitizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/seller/model"
+	"go.uber.org/zap"
+)
+
+func StoreSettings(ctx context.Context, c *fiber.Ctx) (request model.StoreSettings, statusCode int, err error) {
+	ctxt := "SellerSanitizer-StoreSettings"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.BusinessOpeningHour == nil || request.BusinessClosingHour == nil {
+		err = errors.New("business_opening_hour and business_closing_hour are required")
+		return
+	}
+	openingHour, closingHour := *request.BusinessOpeningHour, *request.BusinessClosingHour
+	if !isHour(openingHour) || !isHour(closingHour) {
+		err = errors.New("business hours must be between 0 and 23")
+		return
+	}
+	// closing at or before the opening hour closes the store after midnight,
+	// the same hour for both keeps it open around the clock
+	overnight := closingHour <= openingHour
+	// a delivery hour is the start of an hour long slot, it has to end by closing time
+	for _, deliveryHour := range request.DeliveryHours {
+		inside := deliveryHour >= openingHour && deliveryHour < closingHour
+		if overnight {
+			inside = deliveryHour >= openingHour || deliveryHour < closingHour
+		}
+		if !isHour(deliveryHour) || !inside {
+			err = fmt.Errorf("delivery hour %d is outside business hours", deliveryHour)
+			return
+		}
+	}
+	slices.Sort(request.DeliveryHours)
+	request.DeliveryHours = slices.Compact(request.DeliveryHours)
+	if request.DeliveryHours == nil {
+		request.DeliveryHours = []int{}
+	}
+	if request.MinimumPurchase < 0 {
+		err = errors.New("minimum_purchase must not be negative")
+		return
+	}
+	if request.MerchantNote != nil {
+		if merchantNote := strings.TrimSpace(*request.MerchantNote); merchantNote == "" {
+			request.MerchantNote = nil
+		} else if len([]rune(merchantNote)) > model.MaxMerchantNoteLength {
+			err = fmt.Errorf("merchant_note must not exceed %d characters", model.MaxMerchantNoteLength)
+			return
+		} else {
+			request.MerchantNote = &merchantNote
+		}
+	}
+	if request.DeliveryMaxDistance < 0 || request.DeliveryFreeDistance < 0 || request.DeliveryRate < 0 {
+		err = errors.New("delivery distances and rate must not be negative")
+		return
+	}
+	if request.DeliveryMaxDistance > model.MaxDeliveryDistance {
+		err = fmt.Errorf("delivery_max_distance must not exceed %.0f km", model.MaxDeliveryDistance)
+		return
+	}
+	if request.DeliveryFreeDistance > request.DeliveryMaxDistance {
+		err = errors.New("delivery_free_distance must not exceed delivery_max_distance")
+		return
+	}
+	request.UpdatedAt = nil
+	request.Schedule = nil
+	statusCode = fiber.StatusOK
+	return
+}
+
+func isHour(hour int) bool {
+	return hour >= 0 && hour <= 23
+}
+
+func Coverage(ctx context.Context, c *fiber.Ctx) (request model.CoverageRequest, statusCode int, err error) {
+	ctxt := "SellerSanitizer-Coverage"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if len(request.VillageIDs) == 0 && len(request.SubdistrictIDs) == 0 && len(request.CityIDs) == 0 {
+		err = errors.New("village_ids, subdistrict_ids or city_ids is required")
+		return
+	}
+	for _, ids := range []*[]int64{&request.VillageIDs, &request.SubdistrictIDs, &request.CityIDs} {
+		slices.Sort(*ids)
+		*ids = slices.Compact(*ids)
+	}
+	if request.DeliveryFee != nil && *request.DeliveryFee < 0 {
+		err = errors.New("delivery_fee must not be negative")
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+func Nearby(ctx context.Context, c *fiber.Ctx) (request model.NearbyRequest, statusCode int, err error) {
+	statusCode = fiber.StatusBadRequest
+	if request.Latitude, request.Longitude, request.Located, err = location(ctx, c); err != nil {
+		return
+	}
+	request.Limit = c.QueryInt("limit", model.DefaultNearbyLimit)
+	if request.Limit < 1 || request.Limit > model.MaxNearbyLimit {
+		err = fmt.Errorf("limit must be between 1 and %d", model.MaxNearbyLimit)
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+func DeliveryQuote(ctx context.Context, c *fiber.Ctx) (request model.DeliveryQuoteRequest, statusCode int, err error) {
+	statusCode = fiber.StatusBadRequest
+	if request.SellerID, err = strconv.ParseInt(c.Params("seller_id"), 10, 64); err != nil {
+		err = errors.New("invalid seller_id")
+		return
+	}
+	if request.Latitude, request.Longitude, request.Located, err = location(ctx, c); err != nil {
+		return
+	}
+	if villageID := c.Query("village_id"); villageID != "" {
+		if request.VillageID, err = strconv.ParseInt(villageID, 10, 64); err != nil || request.VillageID < 1 {
+			err = errors.New("invalid village_id")
+			return
+		}
+	}
+	statusCode = fiber.StatusOK
+	return
+}
+
+// location reads the optional latitude and longitude query parameters, which come in pairs
+func location(ctx context.Context, c *fiber.Ctx) (latitude, longitude float64, located bool, err error) {
+	ctxt := "SellerSanitizer-location"
+	latitudeParam, longitudeParam := c.Query("latitude"), c.Query("longitude")
+	if (latitudeParam == "") != (longitudeParam == "") {
+		err = errors.New("latitude and longitude must be given together")
+		return
+	}
+	if latitudeParam == "" {
+		return
+	}
+	if latitude, err = strconv.ParseFloat(latitudeParam, 64); err != nil || !isFinite(latitude) || latitude < -90 || latitude > 90 {
+		if err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParseFloat")
+		}
+		err = errors.New("latitude must be between -90 and 90")
+		return
+	}
+	if longitude, err = strconv.ParseFloat(longitudeParam, 64); err != nil || !isFinite(longitude) || longitude < -180 || longitude > 180 {
+		if err != nil {
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrParseFloat")
+		}
+		err = errors.New("longitude must be between -180 and 180")
+		return
+	}
+	located = true
+	return
+}
+
+// isFinite rejects the NaN and Inf ParseFloat accepts, NaN slips through any range check
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	"github.com/roysitumorang/laukpauk/modules/seller/model"
+	sellerQuery "github.com/roysitumorang/laukpauk/modules/seller/query"
+	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
+	userQuery "github.com/roysitumorang/laukpauk/modules/user/query"
+	"go.uber.org/zap"
+)
+
+type (
+	sellerUseCaseImplementation struct {
+		sellerQuery sellerQuery.SellerQuery
+		userQuery   userQuery.UserQuery
+	}
+)
+
+func NewSellerUseCase(
+	sellerQuery sellerQuery.SellerQuery,
+	userQuery userQuery.UserQuery,
+) SellerUseCase {
+	return &sellerUseCaseImplementation{
+		sellerQuery: sellerQuery,
+		userQuery:   userQuery,
+	}
+}
+
+func (q *sellerUseCaseImplementation) FindSellers(ctx context.Context, filter model.SellerFilter) (response []model.Seller, err error) {
+	ctxt := "SellerUseCase-FindSellers"
+	if response, err = q.sellerQuery.FindSellers(ctx, filter); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSellers")
+		return
+	}
+	now := time.Now()
+	for i := range response {
+		response[i].Schedule = response[i].BusinessHours().ScheduleAt(now)
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) FindSeller(ctx context.Context, sellerID int64) (response model.Seller, err error) {
+	ctxt := "SellerUseCase-FindSeller"
+	sellers, err := q.FindSellers(ctx, model.SellerFilter{SellerIDs: []int64{sellerID}})
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSellers")
+		return
+	}
+	if len(sellers) == 0 {
+		err = errors.New("seller not found")
+		return
+	}
+	response = sellers[0]
+	return
+}
+
+// FindBuyerSellers lists the sellers delivering to villageID, which defaults to the buyer's own village.
+func (q *sellerUseCaseImplementation) FindBuyerSellers(ctx context.Context, buyerID, villageID int64) (response []model.Seller, err error) {
+	ctxt := "SellerUseCase-FindBuyerSellers"
+	if villageID == 0 {
+		buyer, errFind := q.findBuyer(ctx, buyerID)
+		if errFind != nil {
+			err = errFind
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindBuyer")
+			return
+		}
+		villageID = buyer.Village.ID
+	}
+	if response, err = q.FindSellers(ctx, model.SellerFilter{VillageID: villageID}); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSellers")
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) FindNearbySellers(ctx context.Context, buyerID int64, request model.NearbyRequest) (response []model.Seller, err error) {
+	ctxt := "SellerUseCase-FindNearbySellers"
+	if !request.Located {
+		buyer, errFind := q.findBuyer(ctx, buyerID)
+		if errFind != nil {
+			err = errFind
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindBuyer")
+			return
+		}
+		if buyer.Latitude == nil || buyer.Longitude == nil {
+			err = errors.New("latitude and longitude are required, your profile has no location")
+			return
+		}
+		request.Latitude, request.Longitude = *buyer.Latitude, *buyer.Longitude
+	}
+	if response, err = q.sellerQuery.FindNearbySellers(ctx, request); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindNearbySellers")
+		return
+	}
+	now := time.Now()
+	for i := range response {
+		response[i].Schedule = response[i].BusinessHours().ScheduleAt(now)
+	}
+	return
+}
+
+// QuoteDelivery falls back to the buyer's own location when none is given. A village must be covered
+// by the seller, its coverage area fee then wins over the distance based fee.
+func (q *sellerUseCaseImplementation) QuoteDelivery(ctx context.Context, buyerID int64, request model.DeliveryQuoteRequest) (response model.DeliveryQuote, err error) {
+	ctxt := "SellerUseCase-QuoteDelivery"
+	sellers, err := q.sellerQuery.FindSellers(ctx, model.SellerFilter{SellerIDs: []int64{request.SellerID}})
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindSellers")
+		return
+	}
+	if len(sellers) == 0 {
+		err = errors.New("seller not found")
+		return
+	}
+	seller := sellers[0]
+	if !request.Located {
+		buyer, errFind := q.findBuyer(ctx, buyerID)
+		if errFind != nil {
+			err = errFind
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindBuyer")
+			return
+		}
+		if buyer.Latitude != nil && buyer.Longitude != nil {
+			request.Latitude, request.Longitude, request.Located = *buyer.Latitude, *buyer.Longitude, true
+		}
+		if request.VillageID == 0 {
+			request.VillageID = buyer.Village.ID
+		}
+	}
+	if request.Located && seller.Latitude != nil && seller.Longitude != nil {
+		distance := helper.Haversine(*seller.Latitude, *seller.Longitude, request.Latitude, request.Longitude)
+		response.Distance = &distance
+	}
+	if request.VillageID != 0 {
+		coverageAreas, errFind := q.sellerQuery.FindCoverageAreas(ctx, seller.ID)
+		if errFind != nil {
+			err = errFind
+			helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindCoverageAreas")
+			return
+		}
+		i := slices.IndexFunc(coverageAreas, func(coverageArea model.CoverageArea) bool {
+			return coverageArea.Village.ID == request.VillageID
+		})
+		if i == -1 {
+			response.Reason = "village is not covered by the seller"
+			return
+		}
+		if deliveryFee := coverageAreas[i].DeliveryFee; deliveryFee != nil {
+			response.Deliverable = true
+			response.Fee = *deliveryFee
+			return
+		}
+	}
+	if !request.Located {
+		err = errors.New("your location is required, set it on your profile or pass latitude and longitude")
+		return
+	}
+	if response.Distance == nil {
+		err = errors.New("the seller has no location, delivery can't be priced by distance")
+		return
+	}
+	fee, ok := seller.DeliveryFee(*response.Distance)
+	if !ok {
+		response.Reason = "too far from the seller"
+		return
+	}
+	response.Deliverable = true
+	response.Fee = fee
+	return
+}
+
+func (q *sellerUseCaseImplementation) findBuyer(ctx context.Context, buyerID int64) (response userModel.User, err error) {
+	ctxt := "SellerUseCase-findBuyer"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{buyerID},
+			RoleIDs: []int64{roleModel.RoleBuyer},
+			Status:  []int{userModel.StatusActive},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = errors.New("buyer not found")
+		return
+	}
+	response = users[0]
+	return
+}
+
+func (q *sellerUseCaseImplementation) FindStoreSettings(ctx context.Context, userID int64) (response model.StoreSettings, err error) {
+	ctxt := "SellerUseCase-FindStoreSettings"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{userID},
+			RoleIDs: []int64{roleModel.RoleSeller},
+			Status:  []int{userModel.StatusActive},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = errors.New("seller not found")
+		return
+	}
+	user := users[0]
+	response = model.StoreSettings{
+		BusinessOpeningHour:  user.BusinessOpeningHour,
+		BusinessClosingHour:  user.BusinessClosingHour,
+		DeliveryHours:        user.DeliveryHours,
+		MinimumPurchase:      user.MinimumPurchase,
+		MerchantNote:         user.MerchantNote,
+		DeliveryMaxDistance:  user.DeliveryMaxDistance,
+		DeliveryFreeDistance: user.DeliveryFreeDistance,
+		DeliveryRate:         user.DeliveryRate,
+		UpdatedAt:            user.UpdatedAt,
+	}
+	if user.BusinessDays != nil {
+		response.BusinessDays = *user.BusinessDays
+	}
+	if response.DeliveryHours == nil {
+		response.DeliveryHours = []int{}
+	}
+	if user.BusinessDays != nil && user.BusinessOpeningHour != nil && user.BusinessClosingHour != nil {
+		schedule := response.BusinessHours().ScheduleAt(time.Now())
+		response.Schedule = &schedule
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) UpdateStoreSettings(ctx context.Context, currentUser authModel.CurrentUser, request model.StoreSettings) (response model.StoreSettings, err error) {
+	ctxt := "SellerUseCase-UpdateStoreSettings"
+	updatedBy := currentUser.ID
+	if currentUser.ImpersonatorID != 0 {
+		updatedBy = currentUser.ImpersonatorID
+	}
+	userID, err := q.sellerQuery.UpdateStoreSettings(ctx, currentUser.ID, request, updatedBy)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUpdateStoreSettings")
+		return
+	}
+	if userID == 0 {
+		err = errors.New("seller not found")
+		return
+	}
+	if response, err = q.FindStoreSettings(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindStoreSettings")
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) FindCoverageAreas(ctx context.Context, sellerID int64) (response []model.CoverageArea, err error) {
+	ctxt := "SellerUseCase-FindCoverageAreas"
+	if err = q.checkSeller(ctx, sellerID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckSeller")
+		return
+	}
+	if response, err = q.sellerQuery.FindCoverageAreas(ctx, sellerID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindCoverageAreas")
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) AddCoverageAreas(ctx context.Context, sellerID int64, request model.CoverageRequest) (response model.CoverageResponse, err error) {
+	ctxt := "SellerUseCase-AddCoverageAreas"
+	villageIDs, err := q.resolveVillages(ctx, sellerID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResolveVillages")
+		return
+	}
+	if response.Villages, err = q.sellerQuery.AddCoverageAreas(ctx, sellerID, villageIDs, request.DeliveryFee); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrAddCoverageAreas")
+	}
+	return
+}
+
+// SetCoverageDeliveryFee only touches villages already covered, a nil fee falls back to the distance based fee
+func (q *sellerUseCaseImplementation) SetCoverageDeliveryFee(ctx context.Context, sellerID int64, request model.CoverageRequest) (response model.CoverageResponse, err error) {
+	ctxt := "SellerUseCase-SetCoverageDeliveryFee"
+	villageIDs, err := q.resolveVillages(ctx, sellerID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResolveVillages")
+		return
+	}
+	if response.Villages, err = q.sellerQuery.SetCoverageDeliveryFee(ctx, sellerID, villageIDs, request.DeliveryFee); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSetCoverageDeliveryFee")
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) RemoveCoverageAreas(ctx context.Context, sellerID int64, request model.CoverageRequest) (response model.CoverageResponse, err error) {
+	ctxt := "SellerUseCase-RemoveCoverageAreas"
+	villageIDs, err := q.resolveVillages(ctx, sellerID, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrResolveVillages")
+		return
+	}
+	if response.Villages, err = q.sellerQuery.RemoveCoverageAreas(ctx, sellerID, villageIDs); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRemoveCoverageAreas")
+	}
+	return
+}
+
+// resolveVillages expands request into village ids, every region it names must exist.
+func (q *sellerUseCaseImplementation) resolveVillages(ctx context.Context, sellerID int64, request model.CoverageRequest) (response []int64, err error) {
+	ctxt := "SellerUseCase-resolveVillages"
+	if err = q.checkSeller(ctx, sellerID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrCheckSeller")
+		return
+	}
+	villages, err := q.sellerQuery.FindVillages(ctx, request)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindVillages")
+		return
+	}
+	found := map[string]map[int64]bool{
+		"village":     {},
+		"subdistrict": {},
+		"city":        {},
+	}
+	response = make([]int64, len(villages))
+	for i, village := range villages {
+		response[i] = village.Village.ID
+		found["village"][village.Village.ID] = true
+		found["subdistrict"][village.Subdistrict.ID] = true
+		found["city"][village.City.ID] = true
+	}
+	for _, item := range []struct {
+		kind string
+		ids  []int64
+	}{
+		{"village", request.VillageIDs},
+		{"subdistrict", request.SubdistrictIDs},
+		{"city", request.CityIDs},
+	} {
+		if i := slices.IndexFunc(item.ids, func(id int64) bool { return !found[item.kind][id] }); i != -1 {
+			err = fmt.Errorf("%s %d not found", item.kind, item.ids[i])
+			return
+		}
+	}
+	return
+}
+
+func (q *sellerUseCaseImplementation) checkSeller(ctx context.Context, sellerID int64) (err error) {
+	ctxt := "SellerUseCase-checkSeller"
+	users, err := q.userQuery.FindUsers(
+		ctx,
+		userModel.UserFilter{
+			UserIDs: []int64{sellerID},
+			RoleIDs: []int64{roleModel.RoleSeller},
+			Status:  []int{userModel.StatusHold, userModel.StatusActive, userModel.StatusSuspended},
+		},
+	)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrFindUsers")
+		return
+	}
+	if len(users) == 0 {
+		err = errors.New("seller not found")
+	}
+	return
+}
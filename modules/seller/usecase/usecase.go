@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+
+	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
+	"github.com/roysitumorang/laukpauk/modules/seller/model"
+)
+
+type (
+	SellerUseCase interface {
+		FindSellers(ctx context.Context, filter model.SellerFilter) (response []model.Seller, err error)
+		FindSeller(ctx context.Context, sellerID int64) (response model.Seller, err error)
+		FindBuyerSellers(ctx context.Context, buyerID, villageID int64) (response []model.Seller, err error)
+		FindNearbySellers(ctx context.Context, buyerID int64, request model.NearbyRequest) (response []model.Seller, err error)
+		QuoteDelivery(ctx context.Context, buyerID int64, request model.DeliveryQuoteRequest) (response model.DeliveryQuote, err error)
+		FindStoreSettings(ctx context.Context, userID int64) (response model.StoreSettings, err error)
+		UpdateStoreSettings(ctx context.Context, currentUser authModel.CurrentUser, request model.StoreSettings) (response model.StoreSettings, err error)
+		FindCoverageAreas(ctx context.Context, sellerID int64) (response []model.CoverageArea, err error)
+		AddCoverageAreas(ctx context.Context, sellerID int64, request model.CoverageRequest) (response model.CoverageResponse, err error)
+		SetCoverageDeliveryFee(ctx context.Context, sellerID int64, request model.CoverageRequest) (response model.CoverageResponse, err error)
+		RemoveCoverageAreas(ctx context.Context, sellerID int64, request model.CoverageRequest) (response model.CoverageResponse, err error)
+	}
+)
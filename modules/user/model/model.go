@@ -4,6 +4,8 @@ import (
 	"net"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/errors"
 	regionModel "github.com/roysitumorang/laukpauk/modules/region/model"
 	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
 )
@@ -12,19 +14,33 @@ const (
 	StatusHold int = iota
 	StatusActive
 	StatusSuspended = -1
+	StatusDeleted   = -2
+
+	DeletedUserName = "Deleted user"
+
+	GenderMale   = "Pria"
+	GenderFemale = "Wanita"
+)
+
+var (
+	ErrMobilePhoneRegistered = errors.New(fiber.StatusConflict, "mobile phone already registered")
+	ErrEmailRegistered       = errors.New(fiber.StatusConflict, "email already registered")
+
+	Genders = []string{GenderMale, GenderFemale}
 )
 
 type (
 	User struct {
 		ID                   int64              `json:"id"`
 		Role                 roleModel.Role     `json:"role"`
-		ApiKey               *string            `json:"api_key"`
+		ApiKey               *string            `json:"-"`
 		MerchantNote         *string            `json:"merchant_note"`
 		MinimumPurchase      int                `json:"minimum_purchase"`
 		AdminFee             int                `json:"admin_fee"`
 		AccumulationDivisor  int                `json:"accumulation_divisor"`
 		Name                 string             `json:"name"`
 		Email                *string            `json:"email"`
+		EmailVerifiedAt      *time.Time         `json:"email_verified_at"`
 		Password             string             `json:"-"`
 		Address              *string            `json:"address"`
 		Village              regionModel.Region `json:"village"`
@@ -35,8 +51,12 @@ type (
 		DeviceToken          *string            `json:"device_token"`
 		Status               int                `json:"status"`
 		ActivatedAt          *time.Time         `json:"activated_at"`
-		ActivationToken      *string            `json:"activation_token"`
-		PasswordResetToken   *string            `json:"password_reset_token"`
+		ActivationToken      *string            `json:"-"`
+		PasswordResetToken   *string            `json:"-"`
+		SuspendedAt          *time.Time         `json:"suspended_at"`
+		SuspensionReason     *string            `json:"suspension_reason"`
+		DeletionRequestedAt  *time.Time         `json:"deletion_requested_at"`
+		DeletedAt            *time.Time         `json:"deleted_at"`
 		Deposit              float64            `json:"deposit"`
 		Company              *string            `json:"company"`
 		RegistrationIP       net.IP             `json:"registration_ip"`
@@ -70,6 +90,10 @@ type (
 		Saturday  bool `json:"saturday"`
 	}
 
+	SuspendUserRequest struct {
+		Reason string `json:"reason"`
+	}
+
 	UserFilter struct {
 		UserIDs,
 		RoleIDs []int64
@@ -77,3 +101,23 @@ type (
 		Status       []int
 	}
 )
+
+func (q BusinessDays) IsOpenOn(weekday time.Weekday) bool {
+	switch weekday {
+	case time.Sunday:
+		return q.Sunday
+	case time.Monday:
+		return q.Monday
+	case time.Tuesday:
+		return q.Tuesday
+	case time.Wednesday:
+		return q.Wednesday
+	case time.Thursday:
+		return q.Thursday
+	case time.Friday:
+		return q.Friday
+	case time.Saturday:
+		return q.Saturday
+	}
+	return false
+}
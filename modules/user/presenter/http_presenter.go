@@ -0,0 +1,119 @@
+package presenter
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	middlewareJWT "github.com/roysitumorang/laukpauk/middleware/jwt"
+	middlewareRBAC "github.com/roysitumorang/laukpauk/middleware/rbac"
+	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
+	"github.com/roysitumorang/laukpauk/modules/user/sanitizer"
+	userUseCase "github.com/roysitumorang/laukpauk/modules/user/usecase"
+	"go.uber.org/zap"
+)
+
+type (
+	userHTTPHandler struct {
+		authUseCase authUseCase.AuthUseCase
+		userUseCase userUseCase.UserUseCase
+	}
+)
+
+func NewUserHTTPHandler(
+	authUseCase authUseCase.AuthUseCase,
+	userUseCase userUseCase.UserUseCase,
+) *userHTTPHandler {
+	return &userHTTPHandler{
+		authUseCase: authUseCase,
+		userUseCase: userUseCase,
+	}
+}
+
+func (q *userHTTPHandler) Mount(r fiber.Router) {
+	r.Use(middlewareJWT.NewJWT(q.authUseCase), middlewareRBAC.NewRBAC(roleModel.RoleSuperAdmin, roleModel.RoleAdmin)).
+		Put("/:user_id/activate", q.ActivateUser).
+		Put("/:user_id/unlock", q.UnlockLogin).
+		Put("/:user_id/suspend", q.SuspendUser).
+		Put("/:user_id/reactivate", q.ReactivateUser).
+		Post("/:user_id/impersonate", middlewareRBAC.NewRBAC(roleModel.RoleSuperAdmin), q.Impersonate)
+}
+
+func (q *userHTTPHandler) UnlockLogin(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "UserPresenter-UnlockLogin"
+	userID, _ := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err := q.authUseCase.UnlockLogin(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrUnlockLogin")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *userHTTPHandler) SuspendUser(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "UserPresenter-SuspendUser"
+	request, statusCode, err := sanitizer.SuspendUser(ctx, c)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSuspendUser")
+		return helper.NewResponse(statusCode, err.Error(), nil).WriteResponse(c)
+	}
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	userID, _ := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err = q.userUseCase.SuspendUser(ctx, userID, request, currentUser.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSuspendUser")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *userHTTPHandler) ReactivateUser(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "UserPresenter-ReactivateUser"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	userID, _ := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err := q.userUseCase.ReactivateUser(ctx, userID, currentUser.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrReactivateUser")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *userHTTPHandler) ActivateUser(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "UserPresenter-ActivateUser"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	userID, _ := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err := q.userUseCase.ActivateUser(ctx, userID, currentUser.ID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivateUser")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusNoContent, "", nil).WriteResponse(c)
+}
+
+func (q *userHTTPHandler) Impersonate(c *fiber.Ctx) error {
+	ctx := context.Background()
+	ctxt := "UserPresenter-Impersonate"
+	currentUser, ok := middlewareRBAC.GetCurrentUser(c)
+	if !ok {
+		return helper.NewResponse(fiber.StatusUnauthorized, "unauthorized", nil).WriteResponse(c)
+	}
+	userID, _ := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	response, err := q.authUseCase.Impersonate(ctx, currentUser.ID, userID, helper.GetIPAdress(c.Request()))
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrImpersonate")
+		return helper.NewResponse(fiber.StatusBadRequest, err.Error(), nil).WriteResponse(c)
+	}
+	return helper.NewResponse(fiber.StatusOK, "", response).WriteResponse(c)
+}
@@ -2,7 +2,9 @@ package query
 
 import (
 	"context"
+	"time"
 
+	auditModel "github.com/roysitumorang/laukpauk/modules/audit/model"
 	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
 	userModel "github.com/roysitumorang/laukpauk/modules/user/model"
 )
@@ -13,5 +15,18 @@ type (
 		ChangePassword(ctx context.Context, userID int64, encryptedPassword string) (err error)
 		Register(ctx context.Context, request authModel.RegisterRequest) (response *authModel.RegisterResponse, err error)
 		Activate(ctx context.Context, roleID int64, activationToken string) (response int64, err error)
+		ActivateByID(ctx context.Context, userID, updatedBy int64) (response int64, err error)
+		SetActivationToken(ctx context.Context, userID int64, activationToken string, expiresAt time.Time) (err error)
+		DeletePendingUsers(ctx context.Context, createdBefore time.Time) (response int64, err error)
+		SetPasswordResetToken(ctx context.Context, userID int64, passwordResetToken string, expiresAt time.Time) (err error)
+		ResetPassword(ctx context.Context, roleIDs []int64, passwordResetToken, encryptedPassword string) (response int64, err error)
+		SuspendUser(ctx context.Context, userID int64, roleIDs []int64, reason string, updatedBy int64) (response int64, err error)
+		ReactivateUser(ctx context.Context, userID int64, roleIDs []int64, updatedBy int64) (response int64, err error)
+		RequestDeletion(ctx context.Context, userID int64, requestedAt time.Time) (response int64, err error)
+		CancelDeletion(ctx context.Context, userID int64) (response int64, err error)
+		AnonymiseUsers(ctx context.Context, requestedBefore time.Time) (response []int64, err error)
+		ChangeMobilePhone(ctx context.Context, userID int64, mobilePhone string, auditLog auditModel.AuditLog) (response int64, err error)
+		UpdateProfile(ctx context.Context, userID int64, request authModel.UpdateProfileRequest, updatedBy int64) (response int64, err error)
+		ChangeEmail(ctx context.Context, userID int64, email string, auditLog auditModel.AuditLog) (response int64, err error)
 	}
 )
@@ -13,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/roysitumorang/laukpauk/helper"
+	auditModel "github.com/roysitumorang/laukpauk/modules/audit/model"
 	authModel "github.com/roysitumorang/laukpauk/modules/auth/model"
 	"github.com/roysitumorang/laukpauk/modules/user/model"
 	"go.uber.org/zap"
@@ -63,7 +64,8 @@ func (q *userQuery) FindUsers(ctx context.Context, filter model.UserFilter) (res
 			placeholders[i] = fmt.Sprintf("$%d", len(params))
 		}
 		joinedPlaceholders := strings.Join(placeholders, ",")
-		conditions = append(conditions, fmt.Sprintf("(u.mobile_phone IN (%s) OR u.email IN (%s))", joinedPlaceholders, joinedPlaceholders))
+		// unverified emails may belong to someone else, so they never identify a user
+		conditions = append(conditions, fmt.Sprintf("(u.mobile_phone IN (%s) OR (u.email IN (%s) AND u.email_verified_at IS NOT NULL))", joinedPlaceholders, joinedPlaceholders))
 	}
 	if n := len(filter.Status); n > 0 {
 		placeholders := make([]string, n)
@@ -90,6 +92,7 @@ func (q *userQuery) FindUsers(ctx context.Context, filter model.UserFilter) (res
 				, u.accumulation_divisor
 				, u.name
 				, u.email
+				, u.email_verified_at
 				, u.password
 				, u.address
 				, u.village_id
@@ -106,6 +109,10 @@ func (q *userQuery) FindUsers(ctx context.Context, filter model.UserFilter) (res
 				, u.activated_at
 				, u.activation_token
 				, u.password_reset_token
+				, u.suspended_at
+				, u.suspension_reason
+				, u.deletion_requested_at
+				, u.deleted_at
 				, u.deposit
 				, u.company
 				, u.registration_ip
@@ -161,6 +168,7 @@ func (q *userQuery) FindUsers(ctx context.Context, filter model.UserFilter) (res
 			&user.AccumulationDivisor,
 			&user.Name,
 			&user.Email,
+			&user.EmailVerifiedAt,
 			&user.Password,
 			&user.Address,
 			&user.Village.ID,
@@ -177,6 +185,10 @@ func (q *userQuery) FindUsers(ctx context.Context, filter model.UserFilter) (res
 			&user.ActivatedAt,
 			&user.ActivationToken,
 			&user.PasswordResetToken,
+			&user.SuspendedAt,
+			&user.SuspensionReason,
+			&user.DeletionRequestedAt,
+			&user.DeletedAt,
 			&user.Deposit,
 			&user.Company,
 			&user.RegistrationIP,
@@ -263,6 +275,7 @@ func (q *userQuery) Register(ctx context.Context, request authModel.RegisterRequ
 				, village_id
 				, subdistrict_id
 				, activation_token
+				, activation_token_expires_at
 				, minimum_purchase
 				, admin_fee
 				, accumulation_divisor
@@ -271,8 +284,8 @@ func (q *userQuery) Register(ctx context.Context, request authModel.RegisterRequ
 				, registration_ip
 				, created_at
 				, updated_at
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15)
-			RETURNING activation_token`,
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)
+			RETURNING id, activation_token`,
 			userID,
 			request.RoleID,
 			request.Name,
@@ -281,6 +294,7 @@ func (q *userQuery) Register(ctx context.Context, request authModel.RegisterRequ
 			request.VillageID,
 			request.SubdistrictID,
 			activationToken,
+			request.ActivationTokenExpiresAt.UTC(),
 			0,
 			0,
 			0,
@@ -288,7 +302,7 @@ func (q *userQuery) Register(ctx context.Context, request authModel.RegisterRequ
 			0,
 			request.IpAddress,
 			now,
-		).Scan(&response.ActivationToken)
+		).Scan(&response.UserID, &response.ActivationToken)
 		if err != nil {
 			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
 			if errRollback := tx.Rollback(ctx); errRollback != nil {
@@ -320,10 +334,12 @@ func (q *userQuery) Activate(ctx context.Context, roleID int64, activationToken
 		`UPDATE users SET
 			status = $1
 			, activation_token = NULL
+			, activation_token_expires_at = NULL
 			, activated_at = $2
 		WHERE role_id = $3
 		AND status = $4
 		AND activation_token = $5
+		AND activation_token_expires_at > $2
 		RETURNING id`,
 		model.StatusActive,
 		now,
@@ -339,3 +355,598 @@ func (q *userQuery) Activate(ctx context.Context, roleID int64, activationToken
 	}
 	return
 }
+
+func (q *userQuery) ActivateByID(ctx context.Context, userID, updatedBy int64) (response int64, err error) {
+	ctxt := "UserQuery-ActivateByID"
+	now := time.Now().UTC()
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE users SET
+			status = $1
+			, activation_token = NULL
+			, activation_token_expires_at = NULL
+			, activated_at = $2
+			, updated_by = $3
+			, updated_at = $2
+		WHERE id = $4
+		AND status = $5
+		RETURNING id`,
+		model.StatusActive,
+		now,
+		updatedBy,
+		userID,
+		model.StatusHold,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *userQuery) SetActivationToken(ctx context.Context, userID int64, activationToken string, expiresAt time.Time) (err error) {
+	ctxt := "UserQuery-SetActivationToken"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE users SET
+			activation_token = $1
+			, activation_token_expires_at = $2
+		WHERE id = $3
+		AND status = $4`,
+		activationToken,
+		expiresAt.UTC(),
+		userID,
+		model.StatusHold,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+func (q *userQuery) DeletePendingUsers(ctx context.Context, createdBefore time.Time) (response int64, err error) {
+	ctxt := "UserQuery-DeletePendingUsers"
+	commandTag, err := q.dbWrite.Exec(
+		ctx,
+		`DELETE FROM users
+		WHERE status = $1
+		AND activated_at IS NULL
+		AND created_at < $2`,
+		model.StatusHold,
+		createdBefore.UTC(),
+	)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		return
+	}
+	response = commandTag.RowsAffected()
+	return
+}
+
+func (q *userQuery) SetPasswordResetToken(ctx context.Context, userID int64, passwordResetToken string, expiresAt time.Time) (err error) {
+	ctxt := "UserQuery-SetPasswordResetToken"
+	if _, err = q.dbWrite.Exec(
+		ctx,
+		`UPDATE users SET
+			password_reset_token = $1
+			, password_reset_token_expires_at = $2
+		WHERE id = $3`,
+		passwordResetToken,
+		expiresAt.UTC(),
+		userID,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
+
+// ResetPassword takes the hash of the token, only the hash is stored
+func (q *userQuery) ResetPassword(ctx context.Context, roleIDs []int64, passwordResetToken, encryptedPassword string) (response int64, err error) {
+	ctxt := "UserQuery-ResetPassword"
+	now := time.Now().UTC()
+	params := []interface{}{
+		encryptedPassword,
+		now,
+		model.StatusActive,
+		passwordResetToken,
+	}
+	placeholders := make([]string, len(roleIDs))
+	for i, roleID := range roleIDs {
+		params = append(params, roleID)
+		placeholders[i] = fmt.Sprintf("$%d", len(params))
+	}
+	err = q.dbWrite.QueryRow(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE users SET
+				password = $1
+				, password_reset_token = NULL
+				, password_reset_token_expires_at = NULL
+				, updated_by = id
+				, updated_at = $2
+			WHERE status = $3
+			AND password_reset_token = $4
+			AND password_reset_token_expires_at > $2
+			AND role_id IN (%s)
+			RETURNING id`,
+			strings.Join(placeholders, ","),
+		),
+		params...,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *userQuery) SuspendUser(ctx context.Context, userID int64, roleIDs []int64, reason string, updatedBy int64) (response int64, err error) {
+	ctxt := "UserQuery-SuspendUser"
+	now := time.Now().UTC()
+	params := []interface{}{
+		model.StatusSuspended,
+		now,
+		reason,
+		updatedBy,
+		userID,
+		model.StatusActive,
+	}
+	placeholders := make([]string, len(roleIDs))
+	for i, roleID := range roleIDs {
+		params = append(params, roleID)
+		placeholders[i] = fmt.Sprintf("$%d", len(params))
+	}
+	err = q.dbWrite.QueryRow(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE users SET
+				status = $1
+				, suspended_at = $2
+				, suspension_reason = $3
+				, updated_by = $4
+				, updated_at = $2
+			WHERE id = $5
+			AND status = $6
+			AND role_id IN (%s)
+			RETURNING id`,
+			strings.Join(placeholders, ","),
+		),
+		params...,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *userQuery) ReactivateUser(ctx context.Context, userID int64, roleIDs []int64, updatedBy int64) (response int64, err error) {
+	ctxt := "UserQuery-ReactivateUser"
+	now := time.Now().UTC()
+	params := []interface{}{
+		model.StatusActive,
+		now,
+		updatedBy,
+		userID,
+		model.StatusSuspended,
+	}
+	placeholders := make([]string, len(roleIDs))
+	for i, roleID := range roleIDs {
+		params = append(params, roleID)
+		placeholders[i] = fmt.Sprintf("$%d", len(params))
+	}
+	err = q.dbWrite.QueryRow(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE users SET
+				status = $1
+				, suspended_at = NULL
+				, suspension_reason = NULL
+				, updated_by = $3
+				, updated_at = $2
+			WHERE id = $4
+			AND status = $5
+			AND role_id IN (%s)
+			RETURNING id`,
+			strings.Join(placeholders, ","),
+		),
+		params...,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *userQuery) RequestDeletion(ctx context.Context, userID int64, requestedAt time.Time) (response int64, err error) {
+	ctxt := "UserQuery-RequestDeletion"
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE users SET
+			deletion_requested_at = $1
+			, updated_by = $2
+			, updated_at = $1
+		WHERE id = $2
+		AND status = $3
+		AND deletion_requested_at IS NULL
+		RETURNING id`,
+		requestedAt.UTC(),
+		userID,
+		model.StatusActive,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+func (q *userQuery) CancelDeletion(ctx context.Context, userID int64) (response int64, err error) {
+	ctxt := "UserQuery-CancelDeletion"
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE users SET
+			deletion_requested_at = NULL
+			, updated_by = $1
+			, updated_at = $2
+		WHERE id = $1
+		AND status = $3
+		AND deletion_requested_at IS NOT NULL
+		RETURNING id`,
+		userID,
+		time.Now().UTC(),
+		model.StatusActive,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+// AnonymiseUsers wipes the personal data of users whose deletion was requested before requestedBefore,
+// suspended or not. The rows themselves are kept so everything referencing them stays intact. In the
+// same transaction their credentials are revoked, the client details of their sessions, their own ip
+// addresses and contact details in audit logs, and the login failures counted against their identifiers
+// are wiped as well.
+func (q *userQuery) AnonymiseUsers(ctx context.Context, requestedBefore time.Time) (response []int64, err error) {
+	ctxt := "UserQuery-AnonymiseUsers"
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return
+	}
+	now := time.Now().UTC()
+	var loginFailureKeys []string
+	rows, err := tx.Query(
+		ctx,
+		`WITH anonymised AS (
+			SELECT
+				id
+				, mobile_phone
+				, email
+			FROM users
+			WHERE status <> $2
+			AND deletion_requested_at < $4
+			AND deleted_at IS NULL
+			FOR UPDATE
+		)
+		UPDATE users u SET
+			name = $1
+			, mobile_phone = 'deleted-' || u.id
+			, email = NULL
+			, email_verified_at = NULL
+			, address = NULL
+			, registration_ip = NULL
+			, avatar = NULL
+			, thumbnails = NULL
+			, date_of_birth = NULL
+			, gender = NULL
+			, company = NULL
+			, latitude = NULL
+			, longitude = NULL
+			, merchant_note = NULL
+			, device_token = NULL
+			, password = ''
+			, api_key = NULL
+			, activation_token = NULL
+			, password_reset_token = NULL
+			, status = $2
+			, deleted_at = $3
+			, updated_at = $3
+		FROM anonymised a
+		WHERE u.id = a.id
+		RETURNING u.id, a.mobile_phone, a.email`,
+		model.DeletedUserName,
+		model.StatusDeleted,
+		now,
+		requestedBefore.UTC(),
+	)
+	if err == nil {
+		for rows.Next() {
+			var (
+				userID      int64
+				mobilePhone string
+				email       *string
+			)
+			if err = rows.Scan(&userID, &mobilePhone, &email); err != nil {
+				break
+			}
+			response = append(response, userID)
+			// keys as written by the auth use case's login failure tracking
+			loginFailureKeys = append(loginFailureKeys, "identifier:"+mobilePhone)
+			if email != nil {
+				loginFailureKeys = append(loginFailureKeys, "identifier:"+*email)
+			}
+		}
+		rows.Close()
+		if err == nil {
+			err = rows.Err()
+		}
+	}
+	if err == nil && len(response) > 0 {
+		_, err = tx.Exec(
+			ctx,
+			`DELETE FROM login_failures
+			WHERE key = ANY($1)`,
+			loginFailureKeys,
+		)
+	}
+	if err == nil && len(response) > 0 {
+		for _, query := range []string{
+			`UPDATE sessions SET
+				device = NULL
+				, ip_address = NULL
+				, user_agent = NULL
+				, revoked_at = COALESCE(revoked_at, $1)
+				, updated_at = $1
+			WHERE user_id IN (SELECT id FROM users WHERE deleted_at = $1)`,
+			`UPDATE api_keys SET
+				revoked_at = $1
+				, updated_at = $1
+			WHERE revoked_at IS NULL
+			AND user_id IN (SELECT id FROM users WHERE deleted_at = $1)`,
+			`DELETE FROM one_time_passwords
+			WHERE user_id IN (SELECT id FROM users WHERE deleted_at = $1)`,
+			`DELETE FROM user_totps
+			WHERE user_id IN (SELECT id FROM users WHERE deleted_at = $1)`,
+			`DELETE FROM totp_recovery_codes
+			WHERE user_id IN (SELECT id FROM users WHERE deleted_at = $1)`,
+			// identifiers the user had before changing them, read before the audit logs are scrubbed
+			`DELETE FROM login_failures
+			WHERE key IN (
+				SELECT 'identifier:' || i.identifier
+				FROM audit_logs l
+				CROSS JOIN LATERAL (
+					VALUES
+						(l.data->>'old_mobile_phone')
+						, (l.data->>'new_mobile_phone')
+						, (l.data->>'old_email')
+						, (l.data->>'new_email')
+				) AS i (identifier)
+				WHERE i.identifier IS NOT NULL
+				AND l.user_id IN (SELECT id FROM users WHERE deleted_at = $1)
+			)`,
+			`UPDATE audit_logs SET
+				data = data - ARRAY['old_mobile_phone', 'new_mobile_phone', 'old_email', 'new_email']
+			WHERE data ?| ARRAY['old_mobile_phone', 'new_mobile_phone', 'old_email', 'new_email']
+			AND user_id IN (SELECT id FROM users WHERE deleted_at = $1)`,
+			// the ip address is the actor's, records of admins acting on the user keep theirs
+			`UPDATE audit_logs SET
+				ip_address = NULL
+			WHERE ip_address IS NOT NULL
+			AND user_id IN (SELECT id FROM users WHERE deleted_at = $1)
+			AND (actor_id IS NULL OR actor_id = user_id)`,
+		} {
+			if _, err = tx.Exec(ctx, query, now); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		response = nil
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+		response = nil
+	}
+	return
+}
+
+// ChangeMobilePhone swaps the login identifier of an active user and writes the audit record in the same transaction
+func (q *userQuery) ChangeMobilePhone(ctx context.Context, userID int64, mobilePhone string, auditLog auditModel.AuditLog) (response int64, err error) {
+	ctxt := "UserQuery-ChangeMobilePhone"
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return
+	}
+	now := time.Now().UTC()
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE users SET
+			mobile_phone = $1
+			, updated_by = $2
+			, updated_at = $3
+		WHERE id = $4
+		AND status = $5
+		RETURNING id`,
+		mobilePhone,
+		auditLog.ActorID,
+		now,
+		userID,
+		model.StatusActive,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err == nil && response != 0 {
+		auditLog.UserID = response
+		auditLog.CreatedAt = now
+		err = insertAuditLog(ctx, tx, auditLog)
+	}
+	if err != nil || response == 0 {
+		if err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		}
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == pgerrcode.UniqueViolation {
+			err = model.ErrMobilePhoneRegistered
+		}
+		response = 0
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+		response = 0
+	}
+	return
+}
+
+func (q *userQuery) UpdateProfile(ctx context.Context, userID int64, request authModel.UpdateProfileRequest, updatedBy int64) (response int64, err error) {
+	ctxt := "UserQuery-UpdateProfile"
+	err = q.dbWrite.QueryRow(
+		ctx,
+		`UPDATE users SET
+			name = $1
+			, address = $2
+			, village_id = $3
+			, subdistrict_id = $4
+			, gender = $5
+			, date_of_birth = $6
+			, company = $7
+			, updated_by = $8
+			, updated_at = $9
+		WHERE id = $10
+		AND status = $11
+		RETURNING id`,
+		request.Name,
+		request.Address,
+		request.VillageID,
+		request.SubdistrictID,
+		request.Gender,
+		request.BirthDate,
+		request.Company,
+		updatedBy,
+		time.Now().UTC(),
+		userID,
+		model.StatusActive,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+	}
+	return
+}
+
+// ChangeEmail stores an email whose ownership has just been proven, together with its audit record
+func (q *userQuery) ChangeEmail(ctx context.Context, userID int64, email string, auditLog auditModel.AuditLog) (response int64, err error) {
+	ctxt := "UserQuery-ChangeEmail"
+	tx, err := q.dbWrite.Begin(ctx)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrBegin")
+		return
+	}
+	now := time.Now().UTC()
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE users SET
+			email = $1
+			, email_verified_at = $2
+			, updated_by = $3
+			, updated_at = $2
+		WHERE id = $4
+		AND status = $5
+		RETURNING id`,
+		email,
+		now,
+		auditLog.ActorID,
+		userID,
+		model.StatusActive,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	if err == nil && response != 0 {
+		auditLog.UserID = response
+		auditLog.CreatedAt = now
+		err = insertAuditLog(ctx, tx, auditLog)
+	}
+	if err != nil || response == 0 {
+		if err != nil {
+			helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrScan")
+		}
+		if errRollback := tx.Rollback(ctx); errRollback != nil {
+			helper.Capture(ctx, zap.ErrorLevel, errRollback, ctxt, "ErrRollback")
+		}
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == pgerrcode.UniqueViolation {
+			err = model.ErrEmailRegistered
+		}
+		response = 0
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrCommit")
+		response = 0
+	}
+	return
+}
+
+func insertAuditLog(ctx context.Context, tx pgx.Tx, auditLog auditModel.AuditLog) (err error) {
+	ctxt := "UserQuery-insertAuditLog"
+	data, err := json.Marshal(auditLog.Data)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrMarshal")
+		return
+	}
+	auditLogID, err := helper.GenerateSnowflakeUniqueID()
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrGenerateSnowflakeUniqueID")
+		return
+	}
+	if _, err = tx.Exec(
+		ctx,
+		`INSERT INTO audit_logs (
+			id
+			, user_id
+			, actor_id
+			, action
+			, data
+			, ip_address
+			, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		auditLogID,
+		auditLog.UserID,
+		auditLog.ActorID,
+		auditLog.Action,
+		data,
+		auditLog.IpAddress,
+		auditLog.CreatedAt,
+	); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrExec")
+	}
+	return
+}
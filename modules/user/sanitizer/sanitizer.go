@@ -0,0 +1,30 @@
+package sanitizer
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roysitumorang/laukpauk/helper"
+	"github.com/roysitumorang/laukpauk/modules/user/model"
+	"go.uber.org/zap"
+)
+
+func SuspendUser(ctx context.Context, c *fiber.Ctx) (request model.SuspendUserRequest, statusCode int, err error) {
+	ctxt := "UserSanitizer-SuspendUser"
+	statusCode = fiber.StatusBadRequest
+	err = c.BodyParser(&request)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		statusCode = fiberErr.Code
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrBodyParser")
+		return
+	}
+	if request.Reason = strings.TrimSpace(request.Reason); request.Reason == "" {
+		err = errors.New("reason is required")
+		return
+	}
+	statusCode = fiber.StatusOK
+	return
+}
@@ -9,5 +9,10 @@ import (
 type (
 	UserUseCase interface {
 		FindUsers(ctx context.Context, filter model.UserFilter) (response []model.User, err error)
+		SuspendUser(ctx context.Context, userID int64, request model.SuspendUserRequest, suspendedBy int64) (err error)
+		ReactivateUser(ctx context.Context, userID, reactivatedBy int64) (err error)
+		ActivateUser(ctx context.Context, userID, activatedBy int64) (err error)
+		DeletePendingUsers(ctx context.Context) (response int64, err error)
+		AnonymiseUsers(ctx context.Context) (response int64, err error)
 	}
 )
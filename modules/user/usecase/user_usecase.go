@@ -2,24 +2,41 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/roysitumorang/laukpauk/config"
 	"github.com/roysitumorang/laukpauk/helper"
+	authQuery "github.com/roysitumorang/laukpauk/modules/auth/query"
+	roleModel "github.com/roysitumorang/laukpauk/modules/role/model"
 	"github.com/roysitumorang/laukpauk/modules/user/model"
 	userQuery "github.com/roysitumorang/laukpauk/modules/user/query"
+	"github.com/roysitumorang/laukpauk/services/messagingproducer"
 	"go.uber.org/zap"
 )
 
+var (
+	// admins can only suspend customers, admin accounts are managed by super admins outside of the API
+	suspendableRoleIDs = []int64{roleModel.RoleBuyer, roleModel.RoleSeller}
+)
+
 type (
 	userUseCaseImplementation struct {
-		userQuery userQuery.UserQuery
+		userQuery         userQuery.UserQuery
+		authQuery         authQuery.AuthQuery
+		messagingProducer messagingproducer.MessagingProducerService
 	}
 )
 
 func NewUserUseCase(
 	userQuery userQuery.UserQuery,
+	authQuery authQuery.AuthQuery,
+	messagingProducer messagingproducer.MessagingProducerService,
 ) UserUseCase {
 	return &userUseCaseImplementation{
-		userQuery: userQuery,
+		userQuery:         userQuery,
+		authQuery:         authQuery,
+		messagingProducer: messagingProducer,
 	}
 }
 
@@ -30,3 +47,114 @@ func (q *userUseCaseImplementation) FindUsers(ctx context.Context, filter model.
 	}
 	return
 }
+
+func (q *userUseCaseImplementation) SuspendUser(ctx context.Context, userID int64, request model.SuspendUserRequest, suspendedBy int64) (err error) {
+	ctxt := "UserUseCase-SuspendUser"
+	suspendedUserID, err := q.userQuery.SuspendUser(ctx, userID, suspendableRoleIDs, request.Reason, suspendedBy)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrSuspendUser")
+		return
+	}
+	if suspendedUserID == 0 {
+		return errors.New("active buyer or seller not found")
+	}
+	// revoking every session makes the middleware reject access tokens that haven't expired yet
+	if err = q.authQuery.RevokeSessions(ctx, userID); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrRevokeSessions")
+		return
+	}
+	q.publishUserEvent(
+		ctx,
+		map[string]interface{}{
+			"event":        "user.suspended",
+			"user_id":      userID,
+			"reason":       request.Reason,
+			"suspended_by": suspendedBy,
+			"created_at":   time.Now().UTC(),
+		},
+	)
+	return
+}
+
+func (q *userUseCaseImplementation) ReactivateUser(ctx context.Context, userID, reactivatedBy int64) (err error) {
+	ctxt := "UserUseCase-ReactivateUser"
+	reactivatedUserID, err := q.userQuery.ReactivateUser(ctx, userID, suspendableRoleIDs, reactivatedBy)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrReactivateUser")
+		return
+	}
+	if reactivatedUserID == 0 {
+		return errors.New("suspended buyer or seller not found")
+	}
+	q.publishUserEvent(
+		ctx,
+		map[string]interface{}{
+			"event":          "user.reactivated",
+			"user_id":        userID,
+			"reactivated_by": reactivatedBy,
+			"created_at":     time.Now().UTC(),
+		},
+	)
+	return
+}
+
+func (q *userUseCaseImplementation) ActivateUser(ctx context.Context, userID, activatedBy int64) (err error) {
+	ctxt := "UserUseCase-ActivateUser"
+	activatedUserID, err := q.userQuery.ActivateByID(ctx, userID, activatedBy)
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrActivateByID")
+		return
+	}
+	if activatedUserID == 0 {
+		return errors.New("pending user not found")
+	}
+	q.publishUserEvent(
+		ctx,
+		map[string]interface{}{
+			"event":        "user.activated",
+			"user_id":      userID,
+			"activated_by": activatedBy,
+			"created_at":   time.Now().UTC(),
+		},
+	)
+	return
+}
+
+func (q *userUseCaseImplementation) DeletePendingUsers(ctx context.Context) (response int64, err error) {
+	ctxt := "UserUseCase-DeletePendingUsers"
+	if response, err = q.userQuery.DeletePendingUsers(ctx, time.Now().Add(-config.GetPendingUserTTL())); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrDeletePendingUsers")
+	}
+	return
+}
+
+// AnonymiseUsers wipes accounts whose deletion grace period is over
+func (q *userUseCaseImplementation) AnonymiseUsers(ctx context.Context) (response int64, err error) {
+	ctxt := "UserUseCase-AnonymiseUsers"
+	userIDs, err := q.userQuery.AnonymiseUsers(ctx, time.Now().Add(-config.GetAccountDeletionGrace()))
+	if err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrAnonymiseUsers")
+		return
+	}
+	now := time.Now().UTC()
+	for _, userID := range userIDs {
+		q.publishUserEvent(
+			ctx,
+			map[string]interface{}{
+				"event":      "user.deleted",
+				"user_id":    userID,
+				"created_at": now,
+			},
+		)
+	}
+	response = int64(len(userIDs))
+	return
+}
+
+// publishUserEvent only logs failures, the change is already committed when events are published
+func (q *userUseCaseImplementation) publishUserEvent(ctx context.Context, payload map[string]interface{}) {
+	ctxt := "UserUseCase-publishUserEvent"
+	if err := q.messagingProducer.Publish(config.TopicUser, payload); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrPublish")
+	}
+}
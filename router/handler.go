@@ -6,23 +6,38 @@ import (
 	"github.com/roysitumorang/laukpauk/config"
 	"github.com/roysitumorang/laukpauk/helper"
 	"github.com/roysitumorang/laukpauk/migration"
+	apiKeyQuery "github.com/roysitumorang/laukpauk/modules/apikey/query"
+	apiKeyUseCase "github.com/roysitumorang/laukpauk/modules/apikey/usecase"
+	auditQuery "github.com/roysitumorang/laukpauk/modules/audit/query"
+	authQuery "github.com/roysitumorang/laukpauk/modules/auth/query"
 	authUseCase "github.com/roysitumorang/laukpauk/modules/auth/usecase"
 	bannerQuery "github.com/roysitumorang/laukpauk/modules/banner/query"
 	bannerUseCase "github.com/roysitumorang/laukpauk/modules/banner/usecase"
+	oauthQuery "github.com/roysitumorang/laukpauk/modules/oauth/query"
+	oauthUseCase "github.com/roysitumorang/laukpauk/modules/oauth/usecase"
 	regionQuery "github.com/roysitumorang/laukpauk/modules/region/query"
 	regionUseCase "github.com/roysitumorang/laukpauk/modules/region/usecase"
+	sellerQuery "github.com/roysitumorang/laukpauk/modules/seller/query"
+	sellerUseCase "github.com/roysitumorang/laukpauk/modules/seller/usecase"
 	userQuery "github.com/roysitumorang/laukpauk/modules/user/query"
 	userUseCase "github.com/roysitumorang/laukpauk/modules/user/usecase"
+	"github.com/roysitumorang/laukpauk/services/mailer"
+	"github.com/roysitumorang/laukpauk/services/messagingproducer"
+	"github.com/roysitumorang/laukpauk/services/notifier"
+	"github.com/roysitumorang/laukpauk/services/smssender"
 	"go.uber.org/zap"
 )
 
 type (
 	Service struct {
 		Migration     *migration.Migration
+		ApiKeyUseCase apiKeyUseCase.ApiKeyUseCase
 		AuthUseCase   authUseCase.AuthUseCase
 		RegionUseCase regionUseCase.RegionUseCase
+		SellerUseCase sellerUseCase.SellerUseCase
 		UserUseCase   userUseCase.UserUseCase
 		BannerUseCase bannerUseCase.BannerUseCase
+		OAuthUseCase  oauthUseCase.OAuthUseCase
 	}
 )
 
@@ -37,18 +52,33 @@ func MakeHandler() *Service {
 		return nil
 	}
 	migration := migration.NewMigration(tx)
+	apiKeyQuery := apiKeyQuery.NewApiKeyQuery(dbRead, dbWrite)
+	auditQuery := auditQuery.NewAuditQuery(dbRead, dbWrite)
+	authQuery := authQuery.NewAuthQuery(dbRead, dbWrite)
 	bannerQuery := bannerQuery.NewBannerQuery(dbRead, dbWrite)
+	oauthQuery := oauthQuery.NewOAuthQuery(dbRead, dbWrite)
 	regionQuery := regionQuery.NewRegionQuery(dbRead, dbWrite)
+	sellerQuery := sellerQuery.NewSellerQuery(dbRead, dbWrite)
 	userQuery := userQuery.NewUserQuery(dbRead, dbWrite)
-	authUseCase := authUseCase.NewAuthUseCase(userQuery, regionQuery)
+	notifier := notifier.GetNotifierService()
+	smsSender := smssender.GetSMSSenderService()
+	mailer := mailer.GetMailerService()
+	messagingProducer := messagingproducer.GetMessagingProducerService()
+	apiKeyUseCase := apiKeyUseCase.NewApiKeyUseCase(apiKeyQuery, userQuery)
+	authUseCase := authUseCase.NewAuthUseCase(authQuery, auditQuery, userQuery, regionQuery, notifier, smsSender, mailer, messagingProducer)
 	bannerUseCase := bannerUseCase.BannerUseCase(bannerQuery)
+	oauthUseCase := oauthUseCase.NewOAuthUseCase(oauthQuery)
 	regionUseCase := regionUseCase.NewRegionUseCase(regionQuery)
-	userUseCase := userUseCase.NewUserUseCase(userQuery)
+	sellerUseCase := sellerUseCase.NewSellerUseCase(sellerQuery, userQuery)
+	userUseCase := userUseCase.NewUserUseCase(userQuery, authQuery, messagingProducer)
 	return &Service{
 		Migration:     migration,
+		ApiKeyUseCase: apiKeyUseCase,
 		AuthUseCase:   authUseCase,
 		BannerUseCase: bannerUseCase,
+		OAuthUseCase:  oauthUseCase,
 		RegionUseCase: regionUseCase,
+		SellerUseCase: sellerUseCase,
 		UserUseCase:   userUseCase,
 	}
 }
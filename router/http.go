@@ -14,9 +14,13 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/redirect"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/roysitumorang/laukpauk/helper"
+	apiKeyPresenter "github.com/roysitumorang/laukpauk/modules/apikey/presenter"
 	authPresenter "github.com/roysitumorang/laukpauk/modules/auth/presenter"
 	bannerPresenter "github.com/roysitumorang/laukpauk/modules/banner/presenter"
+	oauthPresenter "github.com/roysitumorang/laukpauk/modules/oauth/presenter"
 	regionPresenter "github.com/roysitumorang/laukpauk/modules/region/presenter"
+	sellerPresenter "github.com/roysitumorang/laukpauk/modules/seller/presenter"
+	userPresenter "github.com/roysitumorang/laukpauk/modules/user/presenter"
 	"go.uber.org/zap"
 )
 
@@ -73,9 +77,17 @@ func (q *Service) HTTPServerMain() error {
 	)
 	api := r.Group("/api")
 	v1 := api.Group("/v1")
-	authPresenter.NewAuthHTTPHandler(q.AuthUseCase, q.UserUseCase).Mount(v1.Group("/auth"))
+	apiKeyHTTPHandler := apiKeyPresenter.NewApiKeyHTTPHandler(q.AuthUseCase, q.ApiKeyUseCase)
+	apiKeyHTTPHandler.Mount(v1.Group("/api-keys"))
+	apiKeyHTTPHandler.MountIntegration(v1.Group("/integration"))
+	authHTTPHandler := authPresenter.NewAuthHTTPHandler(q.AuthUseCase, q.UserUseCase)
+	authHTTPHandler.MountWellKnown(r.Group("/.well-known"))
+	authHTTPHandler.Mount(v1.Group("/auth"))
 	bannerPresenter.NewBannerHTTPHandler(q.BannerUseCase).Mount(v1.Group("/banners"))
+	oauthPresenter.NewOAuthHTTPHandler(q.AuthUseCase, q.OAuthUseCase).Mount(v1.Group("/oauth"))
 	regionPresenter.NewRegionHTTPHandler(q.RegionUseCase).Mount(v1.Group("/region"))
+	sellerPresenter.NewSellerHTTPHandler(q.AuthUseCase, q.SellerUseCase).Mount(v1.Group("/sellers"))
+	userPresenter.NewUserHTTPHandler(q.AuthUseCase, q.UserUseCase).Mount(v1.Group("/users"))
 	var port uint16
 	if envPort, ok := os.LookupEnv("PORT"); ok {
 		portInt, err := strconv.Atoi(envPort)
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"go.uber.org/zap"
+)
+
+const (
+	JobInterval = time.Hour
+)
+
+// JobMain runs housekeeping jobs until ctx is done, each job is idempotent so
+// running several app instances at once is harmless.
+func (q *Service) JobMain(ctx context.Context) error {
+	ticker := time.NewTicker(JobInterval)
+	defer ticker.Stop()
+	for {
+		q.runJobs(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Service) runJobs(ctx context.Context) {
+	ctxt := "Router-runJobs"
+	if deletedUsers, err := q.UserUseCase.DeletePendingUsers(ctx); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrDeletePendingUsers")
+	} else if deletedUsers > 0 {
+		helper.Log(ctx, zap.InfoLevel, fmt.Sprintf("deleted %d abandoned registrations", deletedUsers), ctxt, "")
+	}
+	if anonymisedUsers, err := q.UserUseCase.AnonymiseUsers(ctx); err != nil {
+		helper.Log(ctx, zap.ErrorLevel, err.Error(), ctxt, "ErrAnonymiseUsers")
+	} else if anonymisedUsers > 0 {
+		helper.Log(ctx, zap.InfoLevel, fmt.Sprintf("anonymised %d deleted accounts", anonymisedUsers), ctxt, "")
+	}
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultDir = "mails"
+)
+
+var (
+	unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+)
+
+type (
+	// fileMailerService drops every message as an .eml file so developers can open them without an SMTP server
+	fileMailerService struct {
+		dir, from string
+	}
+)
+
+func NewFileMailerService(dir, from string) MailerService {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &fileMailerService{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (s *fileMailerService) Send(ctx context.Context, to, subject, body string) (err error) {
+	ctxt := "MailerFile-Send"
+	if err = os.MkdirAll(s.dir, 0700); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrMkdirAll")
+		return
+	}
+	now := time.Now()
+	fileName := fmt.Sprintf("%d-%s.eml", now.UnixNano(), unsafeFileNameChars.ReplaceAllString(to, "_"))
+	if err = os.WriteFile(filepath.Join(s.dir, fileName), buildMessage(s.from, to, subject, body, now), 0600); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrWriteFile")
+	}
+	return
+}
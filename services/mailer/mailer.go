@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultFrom = "laukpauk <no-reply@laukpauk.local>"
+)
+
+type (
+	MailerService interface {
+		Send(ctx context.Context, to, subject, body string) (err error)
+	}
+)
+
+func GetMailerService() (service MailerService) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = DefaultFrom
+	}
+	switch os.Getenv("MAILER_SERVICE") {
+	case "smtp":
+		service = NewSMTPMailerService(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			from,
+		)
+	case "file":
+		service = NewFileMailerService(os.Getenv("MAILER_DIR"), from)
+	default:
+		log.Fatalln("invalid mailer service provider")
+	}
+	return service
+}
+
+// buildMessage renders a plain text RFC 5322 message, CR and LF are stripped from the headers
+// so user supplied addresses can't inject extra ones.
+func buildMessage(from, to, subject, body string, now time.Time) []byte {
+	headerReplacer := strings.NewReplacer("\r", "", "\n", "")
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "From: %s\r\n", headerReplacer.Replace(from))
+	fmt.Fprintf(&builder, "To: %s\r\n", headerReplacer.Replace(to))
+	fmt.Fprintf(&builder, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerReplacer.Replace(subject)))
+	fmt.Fprintf(&builder, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	builder.WriteString("\r\n")
+	return []byte(builder.String())
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultSMTPPort = "587"
+)
+
+type (
+	smtpMailerService struct {
+		addr, host, username, password, from string
+	}
+)
+
+func NewSMTPMailerService(host, port, username, password, from string) MailerService {
+	if port == "" {
+		port = DefaultSMTPPort
+	}
+	return &smtpMailerService{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *smtpMailerService) Send(ctx context.Context, to, subject, body string) (err error) {
+	ctxt := "MailerSMTP-Send"
+	sender, err := mail.ParseAddress(s.from)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrParseAddress")
+		return
+	}
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	if err = smtp.SendMail(s.addr, auth, sender.Address, []string{to}, buildMessage(s.from, to, subject, body, time.Now())); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrSendMail")
+	}
+	return
+}
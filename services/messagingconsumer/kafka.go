@@ -53,6 +53,8 @@ func (s *kafkaConsumerService) Consume(service *router.Service) {
 	defer os.Exit(0)
 	topics := []string{
 		config.TopicGeneral,
+		config.TopicAuth,
+		config.TopicUser,
 	}
 	consumer := client{ready: make(chan bool), service: service}
 	wg := &sync.WaitGroup{}
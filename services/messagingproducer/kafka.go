@@ -33,6 +33,8 @@ func NewKafkaProducerService(brokers []string) MessagingProducerService {
 	}
 	topics := []string{
 		config.TopicGeneral,
+		config.TopicAuth,
+		config.TopicUser,
 	}
 	for _, topic := range topics {
 		_ = service.Publish(topic, map[string]interface{}{})
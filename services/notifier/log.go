@@ -0,0 +1,23 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"go.uber.org/zap"
+)
+
+type (
+	logNotifierService struct{}
+)
+
+func NewLogNotifierService() NotifierService {
+	return &logNotifierService{}
+}
+
+func (s *logNotifierService) Notify(ctx context.Context, recipient, message string) (err error) {
+	ctxt := "NotifierLog-Notify"
+	helper.Log(ctx, zap.InfoLevel, fmt.Sprintf("notifier: message to %s: %s", recipient, message), ctxt, "")
+	return
+}
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/roysitumorang/laukpauk/services/smssender"
+)
+
+type (
+	NotifierService interface {
+		Notify(ctx context.Context, recipient, message string) (err error)
+	}
+)
+
+func GetNotifierService() (service NotifierService) {
+	switch os.Getenv("NOTIFIER_SERVICE") {
+	case "log":
+		service = NewLogNotifierService()
+	case "sms":
+		service = NewSMSNotifierService(smssender.GetSMSSenderService())
+	default:
+		log.Fatalln("invalid notifier service provider")
+	}
+	return service
+}
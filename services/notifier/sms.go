@@ -0,0 +1,23 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/roysitumorang/laukpauk/services/smssender"
+)
+
+type (
+	smsNotifierService struct {
+		smsSender smssender.SMSSenderService
+	}
+)
+
+func NewSMSNotifierService(smsSender smssender.SMSSenderService) NotifierService {
+	return &smsNotifierService{
+		smsSender: smsSender,
+	}
+}
+
+func (s *smsNotifierService) Notify(ctx context.Context, recipient, message string) (err error) {
+	return s.smsSender.Send(ctx, recipient, message)
+}
@@ -0,0 +1,49 @@
+package smssender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultFilePath = "sms.log"
+)
+
+type (
+	// fileSMSSenderService appends every message to a local file so developers can read the codes without an SMS gateway
+	fileSMSSenderService struct {
+		mu   sync.Mutex
+		path string
+	}
+)
+
+func NewFileSMSSenderService(path string) SMSSenderService {
+	if path == "" {
+		path = DefaultFilePath
+	}
+	return &fileSMSSenderService{
+		path: path,
+	}
+}
+
+func (s *fileSMSSenderService) Send(ctx context.Context, mobilePhone, message string) (err error) {
+	ctxt := "SMSSenderFile-Send"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrOpenFile")
+		return
+	}
+	defer file.Close()
+	if _, err = fmt.Fprintf(file, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), mobilePhone, message); err != nil {
+		helper.Capture(ctx, zap.ErrorLevel, err, ctxt, "ErrFprintf")
+	}
+	return
+}
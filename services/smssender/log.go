@@ -0,0 +1,23 @@
+package smssender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/roysitumorang/laukpauk/helper"
+	"go.uber.org/zap"
+)
+
+type (
+	logSMSSenderService struct{}
+)
+
+func NewLogSMSSenderService() SMSSenderService {
+	return &logSMSSenderService{}
+}
+
+func (s *logSMSSenderService) Send(ctx context.Context, mobilePhone, message string) (err error) {
+	ctxt := "SMSSenderLog-Send"
+	helper.Log(ctx, zap.InfoLevel, fmt.Sprintf("sms: message to %s: %s", mobilePhone, message), ctxt, "")
+	return
+}
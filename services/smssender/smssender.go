@@ -0,0 +1,25 @@
+package smssender
+
+import (
+	"context"
+	"log"
+	"os"
+)
+
+type (
+	SMSSenderService interface {
+		Send(ctx context.Context, mobilePhone, message string) (err error)
+	}
+)
+
+func GetSMSSenderService() (service SMSSenderService) {
+	switch os.Getenv("SMS_SERVICE") {
+	case "log":
+		service = NewLogSMSSenderService()
+	case "file":
+		service = NewFileSMSSenderService(os.Getenv("SMS_FILE_PATH"))
+	default:
+		log.Fatalln("invalid sms service provider")
+	}
+	return service
+}